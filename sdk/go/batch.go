@@ -0,0 +1,191 @@
+package mlld
+
+import (
+	"sync"
+	"time"
+)
+
+// BatchItem describes a single script execution within an ExecuteBatch
+// call.
+type BatchItem struct {
+	// Filepath is the mlld script to run.
+	Filepath string
+
+	// Payload is injected as @payload in the script.
+	Payload any
+
+	// State is injected as @state, overriding any shared seed state for
+	// keys it sets.
+	State map[string]any
+
+	// DynamicModules are injected as importable modules.
+	DynamicModules map[string]any
+}
+
+// BatchOptions controls how ExecuteBatch schedules and executes items.
+type BatchOptions struct {
+	// MaxInFlight bounds how many items run concurrently across the
+	// worker pool. Zero or negative means unbounded (all items at once).
+	MaxInFlight int
+
+	// PerItemTimeout overrides the client default timeout for each item.
+	PerItemTimeout time.Duration
+
+	// FailFast stops starting new items once any item fails: items not yet
+	// started get a BATCH_ABORTED error, items already running finish, and
+	// ExecuteBatch then returns the first failure's error. When false,
+	// every item runs and all errors are collected in the corresponding
+	// BatchResult.
+	FailFast bool
+
+	// SeedState is merged underneath each item's own State, so items can
+	// derive from common shared state without repeating it.
+	SeedState map[string]any
+}
+
+// BatchResult is the outcome of a single BatchItem.
+type BatchResult struct {
+	Output      string
+	StateWrites []StateWrite
+	Err         error
+}
+
+// ExecuteBatch runs items across a bounded pool of workers and returns
+// results in the same order as items, regardless of completion order. When
+// c.Transport is nil (the default subprocess transport), each worker beyond
+// the first gets its own persistent mlld CLI subprocess via batchWorkerPool,
+// so items load-balance across a pool of processes rather than funneling
+// through c's single connection. When c.Transport is set explicitly, its
+// connection can't be safely cloned per worker, so every worker shares c's
+// connection instead, multiplexed by request id the same way concurrent
+// Execute callers already are today. It is a natural extension of chaining
+// Execute calls by hand and threading StateWrites from one call into the
+// next.
+func (c *Client) ExecuteBatch(items []BatchItem, opts *BatchOptions) ([]BatchResult, error) {
+	if opts == nil {
+		opts = &BatchOptions{}
+	}
+
+	results := make([]BatchResult, len(items))
+	if len(items) == 0 {
+		return results, nil
+	}
+
+	maxInFlight := opts.MaxInFlight
+	if maxInFlight <= 0 || maxInFlight > len(items) {
+		maxInFlight = len(items)
+	}
+
+	workers := c.batchWorkerPool(maxInFlight)
+	defer func() {
+		for _, worker := range workers[1:] {
+			_ = worker.Close()
+		}
+	}()
+
+	type job struct {
+		index int
+		item  BatchItem
+	}
+
+	jobs := make(chan job)
+	var stopped sync.Map
+	var wg sync.WaitGroup
+	var firstErr error
+	var firstErrMu sync.Mutex
+
+	runWorker := func(worker *Client) {
+		defer wg.Done()
+		for j := range jobs {
+			if opts.FailFast {
+				if _, stop := stopped.Load("stop"); stop {
+					results[j.index] = BatchResult{Err: &Error{Code: "BATCH_ABORTED", Message: "batch aborted after earlier failure"}}
+					continue
+				}
+			}
+
+			execOpts := &ExecuteOptions{
+				State:          mergeBatchState(opts.SeedState, j.item.State),
+				DynamicModules: j.item.DynamicModules,
+				Timeout:        opts.PerItemTimeout,
+			}
+
+			result, err := worker.Execute(j.item.Filepath, j.item.Payload, execOpts)
+			if err != nil {
+				results[j.index] = BatchResult{Err: err}
+				if opts.FailFast {
+					stopped.Store("stop", true)
+					firstErrMu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					firstErrMu.Unlock()
+				}
+				continue
+			}
+
+			results[j.index] = BatchResult{Output: result.Output, StateWrites: result.StateWrites}
+		}
+	}
+
+	wg.Add(len(workers))
+	for _, worker := range workers {
+		go runWorker(worker)
+	}
+
+	for i, item := range items {
+		jobs <- job{index: i, item: item}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if opts.FailFast && firstErr != nil {
+		return results, firstErr
+	}
+	return results, nil
+}
+
+// batchWorkerPool returns n Clients for ExecuteBatch to load-balance across.
+// The first element is always c itself. If c.Transport is nil, the rest are
+// independent Clients configured like c, each getting its own persistent
+// subprocess lazily on first use; ExecuteBatch closes them once the batch
+// completes. If c.Transport is set, an arbitrary LiveTransport can't be
+// safely duplicated (e.g. TCPTransport/WebSocketTransport hold a single
+// live conn field), so every element is c and workers share its connection.
+func (c *Client) batchWorkerPool(n int) []*Client {
+	workers := make([]*Client, n)
+	workers[0] = c
+
+	if c.Transport != nil {
+		for i := 1; i < n; i++ {
+			workers[i] = c
+		}
+		return workers
+	}
+
+	for i := 1; i < n; i++ {
+		workers[i] = &Client{
+			Command:     c.Command,
+			CommandArgs: c.CommandArgs,
+			Timeout:     c.Timeout,
+			WorkingDir:  c.WorkingDir,
+		}
+	}
+	return workers
+}
+
+// mergeBatchState layers item state on top of seed state, so an item's own
+// keys take precedence over the shared seed.
+func mergeBatchState(seed, item map[string]any) map[string]any {
+	if len(seed) == 0 {
+		return item
+	}
+	merged := make(map[string]any, len(seed)+len(item))
+	for k, v := range seed {
+		merged[k] = v
+	}
+	for k, v := range item {
+		merged[k] = v
+	}
+	return merged
+}
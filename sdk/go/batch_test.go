@@ -0,0 +1,138 @@
+package mlld
+
+import (
+	"bufio"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMergeBatchStateItemOverridesSeed(t *testing.T) {
+	merged := mergeBatchState(map[string]any{"a": 1, "b": 2}, map[string]any{"b": 3})
+	if merged["a"] != 1 || merged["b"] != 3 {
+		t.Fatalf("unexpected merge result: %#v", merged)
+	}
+}
+
+func TestMergeBatchStateEmptySeedReturnsItem(t *testing.T) {
+	item := map[string]any{"a": 1}
+	merged := mergeBatchState(nil, item)
+	if merged["a"] != 1 || len(merged) != 1 {
+		t.Fatalf("expected merge with no seed to equal item, got %#v", merged)
+	}
+}
+
+// TestExecuteBatchRunsEveryItemInOrder uses MaxInFlight: 2 and replies to
+// the two concurrently in-flight requests in reverse arrival order, so the
+// second item to be sent completes first. Results must still land at their
+// original item index, proving order is preserved independent of
+// completion order rather than just of a serialized MaxInFlight: 1 queue.
+func TestExecuteBatchRunsEveryItemInOrder(t *testing.T) {
+	transport := newFakeTransport()
+	client := &Client{Transport: transport, Timeout: time.Second}
+
+	// Requests race to be issued first across the two concurrent workers, so
+	// echo each request's own filepath back as its output rather than
+	// assuming request id order matches item order.
+	type request struct {
+		ID     uint64 `json:"id"`
+		Params struct {
+			Filepath string `json:"filepath"`
+		} `json:"params"`
+	}
+	readRequest := func(scanner *bufio.Scanner) request {
+		scanner.Scan()
+		var req request
+		_ = json.Unmarshal(scanner.Bytes(), &req)
+		return req
+	}
+	reply := func(req request) {
+		transport.send(`{"result":{"id":` + strconv.FormatUint(req.ID, 10) + `,"output":"` + req.Params.Filepath + `"}}`)
+	}
+
+	go func() {
+		scanner := transport.requests()
+
+		first := readRequest(scanner)
+		second := readRequest(scanner)
+		reply(second)
+		reply(first)
+
+		third := readRequest(scanner)
+		reply(third)
+	}()
+
+	items := []BatchItem{
+		{Filepath: "a.mld"},
+		{Filepath: "b.mld"},
+		{Filepath: "c.mld"},
+	}
+
+	results, err := client.ExecuteBatch(items, &BatchOptions{MaxInFlight: 2, PerItemTimeout: time.Second})
+	if err != nil {
+		t.Fatalf("ExecuteBatch failed: %v", err)
+	}
+	if len(results) != len(items) {
+		t.Fatalf("expected %d results, got %d", len(items), len(results))
+	}
+	for i, result := range results {
+		if result.Err != nil {
+			t.Fatalf("item %d failed: %v", i, result.Err)
+		}
+		if result.Output != items[i].Filepath {
+			t.Fatalf("item %d: expected output %q, got %q", i, items[i].Filepath, result.Output)
+		}
+	}
+}
+
+// TestBatchWorkerPoolSharesExplicitTransport guards against duplicating a
+// user-supplied LiveTransport across workers: TCPTransport/WebSocketTransport
+// hold a single live conn field that concurrent Start/Close calls would
+// race on, so every worker must share the same *Client when Transport is set.
+func TestBatchWorkerPoolSharesExplicitTransport(t *testing.T) {
+	transport := newFakeTransport()
+	client := &Client{Transport: transport, Timeout: time.Second}
+
+	workers := client.batchWorkerPool(3)
+	if len(workers) != 3 {
+		t.Fatalf("expected 3 workers, got %d", len(workers))
+	}
+	for i, worker := range workers {
+		if worker != client {
+			t.Fatalf("worker %d: expected shared client, got a distinct one", i)
+		}
+	}
+}
+
+// TestBatchWorkerPoolCreatesIndependentClientsForDefaultTransport guards
+// against ExecuteBatch's worker pool collapsing back into a single shared
+// subprocess connection when no Transport is set: each worker beyond the
+// first should be a distinct *Client configured like the original, so it
+// gets its own persistent subprocess lazily on first use.
+func TestBatchWorkerPoolCreatesIndependentClientsForDefaultTransport(t *testing.T) {
+	client := &Client{Command: "mlld", CommandArgs: []string{"--flag"}, Timeout: time.Second, WorkingDir: "/tmp"}
+
+	workers := client.batchWorkerPool(3)
+	if len(workers) != 3 {
+		t.Fatalf("expected 3 workers, got %d", len(workers))
+	}
+	if workers[0] != client {
+		t.Fatalf("expected first worker to be the original client")
+	}
+
+	seen := map[*Client]bool{client: true}
+	for i, worker := range workers[1:] {
+		if worker == client {
+			t.Fatalf("worker %d: expected an independent client, got the shared one", i+1)
+		}
+		if seen[worker] {
+			t.Fatalf("worker %d: expected a distinct client from every other worker", i+1)
+		}
+		seen[worker] = true
+
+		if worker.Command != client.Command || worker.WorkingDir != client.WorkingDir || worker.Timeout != client.Timeout {
+			t.Fatalf("worker %d: expected config copied from the original client, got %#v", i+1, worker)
+		}
+	}
+}
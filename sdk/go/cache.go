@@ -0,0 +1,194 @@
+package mlld
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// Cache backs Client.Analyze's result cache, keyed by a hash of the
+// analyzed file's contents only. Implementations must
+// be safe for concurrent use. For a cache shared across processes, see the
+// rediscache subpackage's RedisCache, kept out of this package so
+// Process/Execute-only consumers don't inherit its go-redis dependency.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, val []byte, ttl time.Duration)
+	Delete(key string)
+}
+
+// CacheStats reports cumulative cache activity.
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// SetCache installs the cache Client.Analyze consults before making a live
+// RPC. Pass nil to disable caching (the default).
+func (c *Client) SetCache(cache Cache) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.analyzeCache = cache
+}
+
+// InvalidateAnalyze removes any cached Analyze result for filepath's
+// current on-disk contents.
+func (c *Client) InvalidateAnalyze(filepath string) error {
+	c.mu.Lock()
+	cache := c.analyzeCache
+	c.mu.Unlock()
+	if cache == nil {
+		return nil
+	}
+
+	contents, err := os.ReadFile(filepath)
+	if err != nil {
+		return err
+	}
+	cache.Delete(analyzeCacheKey(filepath, contents))
+	return nil
+}
+
+// analyzeCacheKey hashes filepath's contents (the analysis result depends
+// only on module source, not the path) with SHA-256.
+func analyzeCacheKey(filepath string, contents []byte) string {
+	sum := sha256.Sum256(contents)
+	return "analyze:" + hex.EncodeToString(sum[:])
+}
+
+// cachedAnalyze is Analyze with a cache consulted by content hash before
+// falling through to the live RPC.
+func (c *Client) cachedAnalyze(filepath string) (*AnalyzeResult, error) {
+	c.mu.Lock()
+	cache := c.analyzeCache
+	c.mu.Unlock()
+
+	if cache == nil {
+		return c.analyzeUncached(filepath)
+	}
+
+	contents, err := os.ReadFile(filepath)
+	if err != nil {
+		return c.analyzeUncached(filepath)
+	}
+	key := analyzeCacheKey(filepath, contents)
+
+	if cached, ok := cache.Get(key); ok {
+		var result AnalyzeResult
+		if err := json.Unmarshal(cached, &result); err == nil {
+			return &result, nil
+		}
+	}
+
+	result, err := c.analyzeUncached(filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	if serialized, err := json.Marshal(result); err == nil {
+		cache.Set(key, serialized, 0)
+	}
+	return result, nil
+}
+
+// LRUCache is an in-memory, size-bounded Cache.
+type LRUCache struct {
+	maxEntries int
+
+	mu    sync.Mutex
+	order *list.List
+	items map[string]*list.Element
+	stats CacheStats
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewLRUCache creates an in-memory Cache holding up to maxEntries items,
+// evicting the least-recently-used entry once full.
+func NewLRUCache(maxEntries int) *LRUCache {
+	if maxEntries <= 0 {
+		maxEntries = 128
+	}
+	return &LRUCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+func (c *LRUCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	entry := elem.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.items, key)
+		c.stats.Misses++
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	c.stats.Hits++
+	return entry.value, true
+}
+
+func (c *LRUCache) Set(key string, val []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*lruEntry).value = val
+		elem.Value.(*lruEntry).expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	elem := c.order.PushFront(&lruEntry{key: key, value: val, expiresAt: expiresAt})
+	c.items[key] = elem
+
+	if c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		if oldest != nil {
+			c.order.Remove(oldest)
+			delete(c.items, oldest.Value.(*lruEntry).key)
+			c.stats.Evictions++
+		}
+	}
+}
+
+func (c *LRUCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.order.Remove(elem)
+		delete(c.items, key)
+	}
+}
+
+// Stats returns a snapshot of the cache's cumulative hit/miss/eviction
+// counters.
+func (c *LRUCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
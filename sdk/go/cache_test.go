@@ -0,0 +1,73 @@
+package mlld
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUCacheGetSetRoundTrip(t *testing.T) {
+	cache := NewLRUCache(2)
+	cache.Set("a", []byte("1"), 0)
+
+	val, ok := cache.Get("a")
+	if !ok || string(val) != "1" {
+		t.Fatalf("expected cache hit with value 1, got %q ok=%v", val, ok)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 1 {
+		t.Fatalf("expected 1 hit, got %d", stats.Hits)
+	}
+}
+
+func TestLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewLRUCache(2)
+	cache.Set("a", []byte("1"), 0)
+	cache.Set("b", []byte("2"), 0)
+
+	// Touch "a" so "b" becomes the least-recently-used entry.
+	cache.Get("a")
+	cache.Set("c", []byte("3"), 0)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Fatal("expected \"b\" to have been evicted as least-recently-used")
+	}
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("expected \"a\" to survive eviction since it was touched")
+	}
+
+	stats := cache.Stats()
+	if stats.Evictions != 1 {
+		t.Fatalf("expected 1 eviction, got %d", stats.Evictions)
+	}
+}
+
+func TestLRUCacheExpiresByTTL(t *testing.T) {
+	cache := NewLRUCache(2)
+	cache.Set("a", []byte("1"), 10*time.Millisecond)
+
+	time.Sleep(30 * time.Millisecond)
+
+	if _, ok := cache.Get("a"); ok {
+		t.Fatal("expected the entry to have expired")
+	}
+}
+
+func TestLRUCacheDelete(t *testing.T) {
+	cache := NewLRUCache(2)
+	cache.Set("a", []byte("1"), 0)
+	cache.Delete("a")
+
+	if _, ok := cache.Get("a"); ok {
+		t.Fatal("expected the entry to be gone after Delete")
+	}
+}
+
+func TestAnalyzeCacheKeyIsContentAddressed(t *testing.T) {
+	if analyzeCacheKey("a.mld", []byte("x")) != analyzeCacheKey("b.mld", []byte("x")) {
+		t.Fatal("expected the cache key to depend only on contents, not filepath")
+	}
+	if analyzeCacheKey("a.mld", []byte("x")) == analyzeCacheKey("a.mld", []byte("y")) {
+		t.Fatal("expected different contents to produce different cache keys")
+	}
+}
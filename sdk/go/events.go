@@ -0,0 +1,139 @@
+package mlld
+
+import (
+	"sync"
+	"time"
+)
+
+// EventKind identifies the category of a streamed Event.
+type EventKind string
+
+const (
+	EventShow       EventKind = "show"
+	EventStateWrite EventKind = "state:write"
+	EventImport     EventKind = "import"
+	EventOutput     EventKind = "output"
+	EventLog        EventKind = "log"
+	EventError      EventKind = "error"
+)
+
+// Event is a single occurrence emitted by a running script, delivered to
+// ProcessOptions.EventSink/ExecuteOptions.EventSink as it happens rather
+// than only in the aggregated result.
+type Event struct {
+	Kind       EventKind
+	Payload    map[string]any
+	StateWrite *StateWrite
+	Timestamp  time.Time
+}
+
+func eventFromPayload(payload map[string]any) Event {
+	event := Event{Payload: payload, Timestamp: time.Now()}
+
+	kind, _ := payload["type"].(string)
+	event.Kind = EventKind(kind)
+
+	if write, ok := parseStateWriteEvent(payload); ok {
+		event.Kind = EventStateWrite
+		event.StateWrite = &write
+	}
+
+	return event
+}
+
+// coalescer deduplicates rapid-fire writes to the same path within a
+// window, analogous to a task-received sync limit: if multiple writes to
+// the same path occur within CoalesceInterval, only the last is emitted
+// upstream, bounded by CoalesceMaxDelay so a path under continuous writes
+// doesn't starve indefinitely.
+type coalescer struct {
+	interval time.Duration
+	maxDelay time.Duration
+	emit     func(StateWrite)
+
+	mu      sync.Mutex
+	pending map[string]*coalesceEntry
+}
+
+type coalesceEntry struct {
+	write     StateWrite
+	timer     *time.Timer
+	firstSeen time.Time
+}
+
+func newCoalescer(interval, maxDelay time.Duration, emit func(StateWrite)) *coalescer {
+	return &coalescer{
+		interval: interval,
+		maxDelay: maxDelay,
+		emit:     emit,
+		pending:  make(map[string]*coalesceEntry),
+	}
+}
+
+// submit records write, scheduling (or rescheduling) its emission after
+// interval unless that would exceed maxDelay since the path's first
+// buffered write, in which case it is emitted once maxDelay has elapsed
+// since that first write (immediately if it already has).
+func (c *coalescer) submit(write StateWrite) {
+	if c.interval <= 0 {
+		c.emit(write)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.pending[write.Path]
+	if !ok {
+		entry = &coalesceEntry{firstSeen: time.Now()}
+		c.pending[write.Path] = entry
+	}
+	entry.write = write
+
+	if entry.timer != nil {
+		entry.timer.Stop()
+	}
+
+	delay := c.interval
+	if c.maxDelay > 0 {
+		if elapsed := time.Since(entry.firstSeen); elapsed+delay > c.maxDelay {
+			delay = c.maxDelay - elapsed
+			if delay < 0 {
+				delay = 0
+			}
+		}
+	}
+
+	entry.timer = time.AfterFunc(delay, func() {
+		c.flush(write.Path)
+	})
+}
+
+func (c *coalescer) flush(path string) {
+	c.mu.Lock()
+	entry, ok := c.pending[path]
+	if ok {
+		delete(c.pending, path)
+	}
+	c.mu.Unlock()
+
+	if ok {
+		c.emit(entry.write)
+	}
+}
+
+// flushAll emits every still-pending write immediately, used once a request
+// completes so no trailing coalesced write is lost.
+func (c *coalescer) flushAll() {
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = make(map[string]*coalesceEntry)
+	c.mu.Unlock()
+
+	for _, entry := range pending {
+		if entry.timer != nil {
+			entry.timer.Stop()
+		}
+		c.emit(entry.write)
+	}
+}
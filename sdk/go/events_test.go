@@ -0,0 +1,79 @@
+package mlld
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCoalescerCollapsesRapidWritesToSameLastValue(t *testing.T) {
+	var mu sync.Mutex
+	var emitted []StateWrite
+
+	c := newCoalescer(30*time.Millisecond, 0, func(write StateWrite) {
+		mu.Lock()
+		emitted = append(emitted, write)
+		mu.Unlock()
+	})
+
+	c.submit(StateWrite{Path: "progress", Value: 1})
+	c.submit(StateWrite{Path: "progress", Value: 2})
+	c.submit(StateWrite{Path: "progress", Value: 3})
+
+	time.Sleep(80 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(emitted) != 1 {
+		t.Fatalf("expected exactly 1 coalesced emission, got %d: %#v", len(emitted), emitted)
+	}
+	if emitted[0].Value != 3 {
+		t.Fatalf("expected the last write to win, got %#v", emitted[0])
+	}
+}
+
+func TestCoalescerMaxDelayBoundsHoldback(t *testing.T) {
+	var mu sync.Mutex
+	var emitted []StateWrite
+
+	c := newCoalescer(50*time.Millisecond, 60*time.Millisecond, func(write StateWrite) {
+		mu.Lock()
+		emitted = append(emitted, write)
+		mu.Unlock()
+	})
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		c.submit(StateWrite{Path: "progress", Value: i})
+		time.Sleep(20 * time.Millisecond)
+	}
+	elapsed := time.Since(start)
+
+	mu.Lock()
+	count := len(emitted)
+	mu.Unlock()
+
+	if count == 0 {
+		t.Fatalf("expected MaxDelay to force at least one emission within %s of continuous writes", elapsed)
+	}
+}
+
+func TestCoalescerFlushAllEmitsPending(t *testing.T) {
+	var mu sync.Mutex
+	var emitted []StateWrite
+
+	c := newCoalescer(time.Second, 0, func(write StateWrite) {
+		mu.Lock()
+		emitted = append(emitted, write)
+		mu.Unlock()
+	})
+
+	c.submit(StateWrite{Path: "progress", Value: 1})
+	c.flushAll()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(emitted) != 1 || emitted[0].Value != 1 {
+		t.Fatalf("expected flushAll to emit the pending write immediately, got %#v", emitted)
+	}
+}
@@ -0,0 +1,48 @@
+package mlld
+
+import (
+	"bufio"
+	"context"
+	"io"
+)
+
+// fakeTransport is an in-memory LiveTransport for unit tests that need to
+// script server responses and observe outgoing requests without spawning the
+// real CLI subprocess.
+type fakeTransport struct {
+	clientReader *io.PipeReader
+	clientWriter *io.PipeWriter
+	testWriter   *io.PipeWriter
+	testReader   *io.PipeReader
+}
+
+func newFakeTransport() *fakeTransport {
+	clientReader, testWriter := io.Pipe()
+	testReader, clientWriter := io.Pipe()
+	return &fakeTransport{
+		clientReader: clientReader,
+		clientWriter: clientWriter,
+		testWriter:   testWriter,
+		testReader:   testReader,
+	}
+}
+
+func (f *fakeTransport) Start(ctx context.Context) (io.Reader, io.WriteCloser, error) {
+	return f.clientReader, f.clientWriter, nil
+}
+
+func (f *fakeTransport) Close() error {
+	_ = f.clientWriter.Close()
+	_ = f.testWriter.Close()
+	return nil
+}
+
+// send writes a raw line to the client, as if it came from the subprocess.
+func (f *fakeTransport) send(line string) {
+	_, _ = f.testWriter.Write([]byte(line + "\n"))
+}
+
+// requests returns a scanner over lines the client writes out (its requests).
+func (f *fakeTransport) requests() *bufio.Scanner {
+	return bufio.NewScanner(f.testReader)
+}
@@ -1,6 +1,7 @@
 package mlld
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -131,7 +132,7 @@ func TestLiveLoopStopsViaStateUpdate(t *testing.T) {
 		"show \"loop-stopped\"",
 	}, "\n")
 
-	handle, err := client.ProcessAsync(script, &ProcessOptions{
+	handle, err := client.ProcessAsync(context.Background(), script, &ProcessOptions{
 		State:   map[string]any{"exit": false},
 		Mode:    "strict",
 		Timeout: 10 * time.Second,
@@ -210,6 +211,7 @@ func TestLiveStateUpdateFailsAfterCompletion(t *testing.T) {
 	}()
 
 	handle, err := client.ProcessAsync(
+		context.Background(),
 		"show \"done\"",
 		&ProcessOptions{
 			Mode:    "strict",
@@ -0,0 +1,213 @@
+package mlld
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// Invoker performs a single live RPC call. It is the unit Middleware wraps.
+type Invoker func(ctx context.Context, method string, params any) (map[string]any, []StateWrite, error)
+
+// Middleware wraps an Invoker to add cross-cutting behavior (logging, retry,
+// and optionally tracing/metrics via the otelmiddleware/prommiddleware
+// subpackages) around every live RPC call.
+type Middleware func(next Invoker) Invoker
+
+// Use registers middlewares, applied in the order given: the first
+// middleware passed sees the call first and the result last, wrapping
+// everything after it.
+func (c *Client) Use(mw ...Middleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.middlewares = append(c.middlewares, mw...)
+}
+
+// baseInvoker is the terminal Invoker that actually performs the live RPC,
+// with no middleware applied. The live request ID isn't known until
+// startRequest returns it, so it's reported back to any middleware that
+// called WithRequestID via setRequestID rather than passed as an Invoker
+// argument; see WithRequestID.
+func (c *Client) baseInvoker(timeout time.Duration) Invoker {
+	return func(ctx context.Context, method string, params any) (map[string]any, []StateWrite, error) {
+		requestID, responseCh, err := c.startRequest(method, params)
+		if err != nil {
+			return nil, nil, err
+		}
+		setRequestID(ctx, requestID)
+		return c.awaitRequestWithEvents(ctx, requestID, responseCh, timeout, nil, nil)
+	}
+}
+
+// chainedInvoker wraps baseInvoker with every registered middleware, in
+// registration order.
+func (c *Client) chainedInvoker(timeout time.Duration) Invoker {
+	c.mu.Lock()
+	mws := append([]Middleware(nil), c.middlewares...)
+	c.mu.Unlock()
+
+	invoker := c.baseInvoker(timeout)
+	for i := len(mws) - 1; i >= 0; i-- {
+		invoker = mws[i](invoker)
+	}
+	return invoker
+}
+
+// asyncAwaitInvoker is baseInvoker's counterpart for a request that
+// ProcessAsync/ExecuteAsync already sent: it awaits requestID/responseCh
+// instead of calling startRequest itself, since the caller needs the
+// request's id back immediately to build a handle rather than waiting for
+// the whole round trip. Because the request is already in flight, retrying
+// this Invoker can't resend it: it would just re-await the same
+// already-drained responseCh. RetryMiddleware recognizes this case via
+// markAsyncAwait and skips its loop accordingly; use Client.SetRetryPolicy
+// (see withRetry) to retry Process/Execute themselves.
+func (c *Client) asyncAwaitInvoker(requestID uint64, responseCh <-chan liveMessage, timeout time.Duration, onStateWrite func(StateWrite), eventSink func(Event)) Invoker {
+	return func(ctx context.Context, method string, params any) (map[string]any, []StateWrite, error) {
+		setRequestID(ctx, requestID)
+		return c.awaitRequestWithEvents(ctx, requestID, responseCh, timeout, onStateWrite, eventSink)
+	}
+}
+
+// chainedAsyncAwaitInvoker wraps asyncAwaitInvoker with every registered
+// middleware, so LoggingMiddleware (and any otelmiddleware/prommiddleware
+// middleware the caller registers) sees Process/Execute/ProcessAsync/
+// ExecuteAsync calls the same way it sees Analyze/UpdateState. The ctx
+// passed to the returned Invoker must be tagged with markAsyncAwait so
+// RetryMiddleware knows not to retry it.
+func (c *Client) chainedAsyncAwaitInvoker(requestID uint64, responseCh <-chan liveMessage, timeout time.Duration, onStateWrite func(StateWrite), eventSink func(Event)) Invoker {
+	c.mu.Lock()
+	mws := append([]Middleware(nil), c.middlewares...)
+	c.mu.Unlock()
+
+	invoker := c.asyncAwaitInvoker(requestID, responseCh, timeout, onStateWrite, eventSink)
+	for i := len(mws) - 1; i >= 0; i-- {
+		invoker = mws[i](invoker)
+	}
+	return invoker
+}
+
+// asyncAwaitContextKey marks a context as belonging to an asyncAwaitInvoker
+// call, so RetryMiddleware can tell it apart from a baseInvoker call it can
+// actually resend.
+type asyncAwaitContextKey struct{}
+
+// markAsyncAwait tags ctx for chainedAsyncAwaitInvoker; see asyncAwaitInvoker.
+func markAsyncAwait(ctx context.Context) context.Context {
+	return context.WithValue(ctx, asyncAwaitContextKey{}, true)
+}
+
+func isAsyncAwait(ctx context.Context) bool {
+	tagged, _ := ctx.Value(asyncAwaitContextKey{}).(bool)
+	return tagged
+}
+
+type requestIDContextKey struct{}
+
+// requestIDHolder carries the live request ID from the terminal Invoker
+// (baseInvoker/asyncAwaitInvoker) back up to the middleware that asked for
+// it. There's no concurrency to guard: the same goroutine that calls
+// WithRequestID and then next(ctx, ...) is the one that later calls the
+// returned func, by which point the terminal Invoker either has set id or
+// has already returned.
+type requestIDHolder struct {
+	id uint64
+	ok bool
+}
+
+// WithRequestID returns a context a middleware can pass to next so the
+// terminal Invoker reports the live request ID it assigns, along with a
+// func to read it back once next returns. The ID isn't known until
+// startRequest/ProcessAsync/ExecuteAsync assigns it, so it can't be an
+// Invoker parameter; see otelmiddleware.Tracing for an example consumer.
+func WithRequestID(ctx context.Context) (context.Context, func() (uint64, bool)) {
+	holder := new(requestIDHolder)
+	return context.WithValue(ctx, requestIDContextKey{}, holder), func() (uint64, bool) {
+		return holder.id, holder.ok
+	}
+}
+
+// setRequestID records id in ctx's requestIDHolder, if the caller asked for
+// one via WithRequestID. It's a no-op otherwise.
+func setRequestID(ctx context.Context, id uint64) {
+	if holder, ok := ctx.Value(requestIDContextKey{}).(*requestIDHolder); ok {
+		holder.id = id
+		holder.ok = true
+	}
+}
+
+// LoggingMiddleware logs every live RPC call and its outcome via logger,
+// including the live request ID once the call has assigned one.
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	return func(next Invoker) Invoker {
+		return func(ctx context.Context, method string, params any) (map[string]any, []StateWrite, error) {
+			ctx, requestID := WithRequestID(ctx)
+			start := time.Now()
+			result, writes, err := next(ctx, method, params)
+			attrs := []any{"method", method, "durationMs", time.Since(start).Milliseconds()}
+			if id, ok := requestID(); ok {
+				attrs = append(attrs, "requestId", id)
+			}
+			if err != nil {
+				var requestErr *Error
+				if errors.As(err, &requestErr) {
+					attrs = append(attrs, "errorCode", requestErr.Code)
+				}
+				logger.ErrorContext(ctx, "mlld live call failed", append(attrs, "error", err)...)
+				return result, writes, err
+			}
+			logger.DebugContext(ctx, "mlld live call succeeded", attrs...)
+			return result, writes, nil
+		}
+	}
+}
+
+// RetryMiddleware automatically retries a call on TRANSPORT_ERROR or
+// TIMEOUT with jittered exponential backoff, per policy. It is a no-op on
+// the Process/Execute/ProcessAsync/ExecuteAsync async-await path (see
+// asyncAwaitInvoker): those requests are already in flight by the time this
+// middleware runs, so "retrying" would just re-await the same
+// already-drained responseCh instead of resending the request. Use
+// Client.SetRetryPolicy to retry Process/Execute themselves.
+func RetryMiddleware(policy RetryPolicy) Middleware {
+	return func(next Invoker) Invoker {
+		return func(ctx context.Context, method string, params any) (map[string]any, []StateWrite, error) {
+			if isAsyncAwait(ctx) {
+				return next(ctx, method, params)
+			}
+
+			maxAttempts := policy.MaxAttempts
+			if maxAttempts < 1 {
+				maxAttempts = 1
+			}
+
+			var result map[string]any
+			var writes []StateWrite
+			var lastErr error
+
+			for attempt := 1; attempt <= maxAttempts; attempt++ {
+				result, writes, lastErr = next(ctx, method, params)
+				if lastErr == nil {
+					return result, writes, nil
+				}
+
+				var requestErr *Error
+				retryable := errors.As(lastErr, &requestErr) && (requestErr.Code == "TRANSPORT_ERROR" || requestErr.Code == "TIMEOUT")
+				if !retryable || attempt >= maxAttempts {
+					break
+				}
+
+				if d := policy.delay(attempt); d > 0 {
+					select {
+					case <-time.After(d):
+					case <-ctx.Done():
+						return nil, writes, ctx.Err()
+					}
+				}
+			}
+
+			return result, writes, lastErr
+		}
+	}
+}
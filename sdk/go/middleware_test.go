@@ -0,0 +1,146 @@
+package mlld
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestProcessAsyncGoesThroughMiddlewareChain guards against Process/Execute/
+// ProcessAsync/ExecuteAsync silently bypassing Use()'d middleware, which
+// only Analyze and UpdateState used to go through.
+func TestProcessAsyncGoesThroughMiddlewareChain(t *testing.T) {
+	transport := newFakeTransport()
+	client := &Client{Transport: transport, Timeout: time.Second}
+
+	var mu sync.Mutex
+	var seen []string
+	client.Use(func(next Invoker) Invoker {
+		return func(ctx context.Context, method string, params any) (map[string]any, []StateWrite, error) {
+			mu.Lock()
+			seen = append(seen, method)
+			mu.Unlock()
+			return next(ctx, method, params)
+		}
+	})
+
+	go func() {
+		scanner := transport.requests()
+		for scanner.Scan() {
+		}
+	}()
+
+	handle, err := client.ProcessAsync(context.Background(), "show \"hi\"", &ProcessOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("ProcessAsync failed: %v", err)
+	}
+
+	requestID := strconv.FormatUint(handle.RequestID(), 10)
+	transport.send(`{"result":{"id":` + requestID + `,"output":"hi"}}`)
+
+	if _, err := handle.Result(); err != nil {
+		t.Fatalf("Result failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != 1 || seen[0] != "process" {
+		t.Fatalf("expected middleware to see exactly one \"process\" call, got %v", seen)
+	}
+}
+
+// TestRetryMiddlewareDoesNotRetryAsyncAwait guards against RetryMiddleware
+// re-awaiting the same already-drained responseCh on Process/Execute's
+// async-await path instead of failing fast: since the request is already in
+// flight by the time RetryMiddleware runs there, "retrying" it can't
+// resend and would just multiply the timeout by MaxAttempts.
+func TestRetryMiddlewareDoesNotRetryAsyncAwait(t *testing.T) {
+	transport := newFakeTransport()
+	client := &Client{Transport: transport, Timeout: time.Second}
+
+	go func() {
+		scanner := transport.requests()
+		for scanner.Scan() {
+		}
+	}()
+
+	client.Use(RetryMiddleware(RetryPolicy{MaxAttempts: 3}))
+
+	handle, err := client.ProcessAsync(context.Background(), "show \"hi\"", &ProcessOptions{Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("ProcessAsync failed: %v", err)
+	}
+
+	start := time.Now()
+	_, err = handle.Result()
+	elapsed := time.Since(start)
+
+	var requestErr *Error
+	if !errors.As(err, &requestErr) || requestErr.Code != "TIMEOUT" {
+		t.Fatalf("expected TIMEOUT error, got %v", err)
+	}
+	if elapsed >= 150*time.Millisecond {
+		t.Fatalf("expected a single ~50ms timeout, took %s (looks like RetryMiddleware retried the async-await path)", elapsed)
+	}
+}
+
+// TestWithRequestIDReportsAssignedIDOnBothInvokerPaths guards against
+// WithRequestID's holder silently staying empty: a middleware using it to
+// tag spans/logs needs the real live request ID back on both the
+// asyncAwaitInvoker path (Process/Execute) and the baseInvoker path
+// (Analyze/UpdateState), where the ID isn't known until startRequest runs.
+func TestWithRequestIDReportsAssignedIDOnBothInvokerPaths(t *testing.T) {
+	transport := newFakeTransport()
+	client := &Client{Transport: transport, Timeout: time.Second}
+
+	var mu sync.Mutex
+	seen := map[string]uint64{}
+	client.Use(func(next Invoker) Invoker {
+		return func(ctx context.Context, method string, params any) (map[string]any, []StateWrite, error) {
+			ctx, requestID := WithRequestID(ctx)
+			result, writes, err := next(ctx, method, params)
+			if id, ok := requestID(); ok {
+				mu.Lock()
+				seen[method] = id
+				mu.Unlock()
+			}
+			return result, writes, err
+		}
+	})
+
+	go func() {
+		scanner := transport.requests()
+		for scanner.Scan() {
+			var req struct {
+				ID uint64 `json:"id"`
+			}
+			_ = json.Unmarshal(scanner.Bytes(), &req)
+			transport.send(`{"result":{"id":` + strconv.FormatUint(req.ID, 10) + `,"output":"hi"}}`)
+		}
+	}()
+
+	handle, err := client.ProcessAsync(context.Background(), `show "hi"`, &ProcessOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("ProcessAsync failed: %v", err)
+	}
+	if _, err := handle.Result(); err != nil {
+		t.Fatalf("Result failed: %v", err)
+	}
+
+	if _, err := client.Analyze("module.mld"); err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if seen["process"] != handle.RequestID() {
+		t.Fatalf("expected process requestID %d, got %d", handle.RequestID(), seen["process"])
+	}
+	if _, ok := seen["analyze"]; !ok {
+		t.Fatalf("expected analyze to report a requestID, got %v", seen)
+	}
+}
@@ -16,7 +16,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os/exec"
+	"os"
 	"strconv"
 	"strings"
 	"sync"
@@ -38,13 +38,38 @@ type Client struct {
 	// Working directory for script execution.
 	WorkingDir string
 
-	mu          sync.Mutex
-	writeMu     sync.Mutex
-	liveCmd     *exec.Cmd
-	liveIn      io.WriteCloser
-	livePending map[uint64]chan liveMessage
-	liveStderr  bytes.Buffer
-	nextID      uint64
+	// Transport selects how the live RPC connection is established. If
+	// nil, Client defaults to a StdioTransport running Command as a
+	// subprocess, preserving pre-Transport behavior.
+	Transport LiveTransport
+
+	mu            sync.Mutex
+	writeMu       sync.Mutex
+	liveTransport LiveTransport
+	liveIn        io.WriteCloser
+	livePending   map[uint64]chan liveMessage
+	nextID        uint64
+
+	// liveGen identifies the current liveTransport/livePending generation.
+	// readLoop captures the generation it was started for and passes it to
+	// failAllPending, which no-ops if liveGen has since moved on: without
+	// this, a readLoop reading a transport that resetLiveLocked already
+	// replaced could still observe EOF on the old pipe after a newer
+	// generation is already in flight, and clobber its livePending map out
+	// from under it.
+	liveGen uint64
+
+	signalCh        chan os.Signal
+	shuttingDown    bool
+	gracePeriodOver bool
+	shutdownOpts    ShutdownOptions
+
+	retry         RetryPolicy
+	faultInjector FaultInjector
+
+	middlewares []Middleware
+
+	analyzeCache Cache
 }
 
 type liveMessageKind string
@@ -107,6 +132,35 @@ type ProcessOptions struct {
 
 	// Timeout overrides the client default.
 	Timeout time.Duration
+
+	// EventSink, if set, receives typed Events as they occur rather than
+	// only in the aggregated StateWrites returned by Result. The
+	// aggregated slice is still populated for callers that don't opt in.
+	EventSink func(Event)
+
+	// CoalesceInterval, if positive, collapses multiple StateWrites to
+	// the same path arriving within the interval into the last one before
+	// they reach EventSink, Events() and WatchState subscribers. The CLI
+	// still sends every write, and the aggregated StateWrites slice still
+	// holds all of them. Zero disables coalescing.
+	CoalesceInterval time.Duration
+
+	// CoalesceMaxDelay bounds how long a coalesced write can be held back
+	// under continuous writes to the same path. Zero means no bound.
+	CoalesceMaxDelay time.Duration
+
+	// EventFilter restricts which event kinds are delivered on the
+	// handle's Events() channel. An empty filter delivers every kind.
+	EventFilter []EventKind
+
+	// EventBackpressure selects how the Events() channel behaves when the
+	// consumer falls behind. Defaults to EventBackpressureDropOldest.
+	EventBackpressure EventBackpressure
+
+	// Cacheable marks this script as pure and side-effect-free. Process
+	// does not read it and does not consult the Analyze cache, so setting
+	// it has no effect.
+	Cacheable bool
 }
 
 // ExecuteOptions configures an Execute call.
@@ -128,6 +182,30 @@ type ExecuteOptions struct {
 
 	// Timeout overrides the client default.
 	Timeout time.Duration
+
+	// EventSink, if set, receives typed Events as they occur rather than
+	// only in the aggregated StateWrites returned by Result. The
+	// aggregated slice is still populated for callers that don't opt in.
+	EventSink func(Event)
+
+	// CoalesceInterval, if positive, collapses multiple StateWrites to
+	// the same path arriving within the interval into the last one before
+	// they reach EventSink, Events() and WatchState subscribers. The CLI
+	// still sends every write, and the aggregated StateWrites slice still
+	// holds all of them. Zero disables coalescing.
+	CoalesceInterval time.Duration
+
+	// CoalesceMaxDelay bounds how long a coalesced write can be held back
+	// under continuous writes to the same path. Zero means no bound.
+	CoalesceMaxDelay time.Duration
+
+	// EventFilter restricts which event kinds are delivered on the
+	// handle's Events() channel. An empty filter delivers every kind.
+	EventFilter []EventKind
+
+	// EventBackpressure selects how the Events() channel behaves when the
+	// consumer falls behind. Defaults to EventBackpressureDropOldest.
+	EventBackpressure EventBackpressure
 }
 
 // ExecuteResult contains structured output from Execute.
@@ -203,19 +281,97 @@ type Needs struct {
 
 type requestHandle struct {
 	client     *Client
+	method     string
 	requestID  uint64
 	responseCh <-chan liveMessage
 	timeout    time.Duration
+	ctx        context.Context
 
 	once        sync.Once
 	result      map[string]any
 	stateWrites []StateWrite
 	err         error
+
+	watchMu     sync.Mutex
+	watches     []*watchSubscription
+	stateByPath map[string]any
+
+	eventSink         func(Event)
+	eventFilter       []EventKind
+	eventBackpressure EventBackpressure
+	events            chan Event
+	eventsClosed      sync.Once
+	coalesceInterval  time.Duration
+	coalesceMaxDelay  time.Duration
+	coalescer         *coalescer
+}
+
+// newRequestHandle builds a requestHandle, sizing and filtering its Events()
+// channel according to opts, and starts the pump that reads responseCh
+// immediately. The pump can't wait for a Result()/Wait() call to kick it off:
+// WatchState and Events() subscribers are entitled to see writes as they
+// happen, not only once something else happens to be blocked in Result().
+func newRequestHandle(client *Client, method string, requestID uint64, responseCh <-chan liveMessage, timeout time.Duration, ctx context.Context, eventSink func(Event), eventFilter []EventKind, backpressure EventBackpressure, coalesceInterval, coalesceMaxDelay time.Duration) *requestHandle {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	h := &requestHandle{
+		client:            client,
+		method:            method,
+		requestID:         requestID,
+		responseCh:        responseCh,
+		timeout:           timeout,
+		ctx:               ctx,
+		eventSink:         eventSink,
+		eventFilter:       eventFilter,
+		eventBackpressure: backpressure,
+		events:            make(chan Event, 128),
+		coalesceInterval:  coalesceInterval,
+		coalesceMaxDelay:  coalesceMaxDelay,
+	}
+	go h.wait()
+	return h
 }
 
 func (h *requestHandle) wait() (map[string]any, []StateWrite, error) {
 	h.once.Do(func() {
-		h.result, h.stateWrites, h.err = h.client.awaitRequest(h.requestID, h.responseCh, h.timeout)
+		emit := func(event Event) {
+			if h.eventSink != nil {
+				h.eventSink(event)
+			}
+			h.deliverEvent(event)
+		}
+
+		onStateWrite := func(write StateWrite) {
+			h.fanOutStateWrite(write)
+			emit(Event{Kind: EventStateWrite, StateWrite: &write, Timestamp: write.Timestamp})
+		}
+
+		if h.coalesceInterval > 0 {
+			h.coalescer = newCoalescer(h.coalesceInterval, h.coalesceMaxDelay, onStateWrite)
+		}
+
+		dispatchStateWrite := onStateWrite
+		if h.coalescer != nil {
+			dispatchStateWrite = h.coalescer.submit
+		}
+
+		h.client.mu.Lock()
+		hasMiddleware := len(h.client.middlewares) > 0
+		h.client.mu.Unlock()
+
+		if hasMiddleware {
+			invoke := h.client.chainedAsyncAwaitInvoker(h.requestID, h.responseCh, h.timeout, dispatchStateWrite, emit)
+			h.result, h.stateWrites, h.err = invoke(markAsyncAwait(h.ctx), h.method, nil)
+		} else {
+			h.result, h.stateWrites, h.err = h.client.awaitRequestWithEvents(h.ctx, h.requestID, h.responseCh, h.timeout, dispatchStateWrite, emit)
+		}
+
+		if h.coalescer != nil {
+			h.coalescer.flushAll()
+		}
+		h.closeWatches()
+		h.closeEvents()
 	})
 	return h.result, h.stateWrites, h.err
 }
@@ -296,17 +452,28 @@ func (h *ExecuteHandle) Result() (*ExecuteResult, error) {
 	return decodeExecuteResult(result, stateWriteEvents)
 }
 
-// Process executes an mlld script string and returns the output.
+// Process executes an mlld script string and returns the output. Transient
+// transport errors are retried according to Client.SetRetryPolicy.
 func (c *Client) Process(script string, opts *ProcessOptions) (string, error) {
-	handle, err := c.ProcessAsync(script, opts)
+	var output string
+	err := c.withRetry(func() error {
+		handle, err := c.ProcessAsync(context.Background(), script, opts)
+		if err != nil {
+			return err
+		}
+		output, err = handle.Result()
+		return err
+	})
 	if err != nil {
 		return "", err
 	}
-	return handle.Result()
+	return output, nil
 }
 
-// ProcessAsync executes an mlld script string and returns an in-flight handle.
-func (c *Client) ProcessAsync(script string, opts *ProcessOptions) (*ProcessHandle, error) {
+// ProcessAsync executes an mlld script string and returns an in-flight
+// handle. ctx bounds the request: canceling it has the same effect as
+// calling handle.Cancel(), so Cancel() isn't the only way to abort.
+func (c *Client) ProcessAsync(ctx context.Context, script string, opts *ProcessOptions) (*ProcessHandle, error) {
 	if opts == nil {
 		opts = &ProcessOptions{}
 	}
@@ -341,27 +508,34 @@ func (c *Client) ProcessAsync(script string, opts *ProcessOptions) (*ProcessHand
 		return nil, err
 	}
 
-	return &ProcessHandle{
-		request: &requestHandle{
-			client:     c,
-			requestID:  requestID,
-			responseCh: responseCh,
-			timeout:    c.resolveTimeout(opts.Timeout),
-		},
-	}, nil
+	request := newRequestHandle(c, "process", requestID, responseCh, c.resolveTimeout(opts.Timeout), ctx, opts.EventSink, opts.EventFilter, opts.EventBackpressure, opts.CoalesceInterval, opts.CoalesceMaxDelay)
+
+	return &ProcessHandle{request: request}, nil
 }
 
-// Execute runs an mlld file with a payload and optional state.
+// Execute runs an mlld file with a payload and optional state. Transient
+// transport errors are retried according to Client.SetRetryPolicy.
 func (c *Client) Execute(filepath string, payload any, opts *ExecuteOptions) (*ExecuteResult, error) {
-	handle, err := c.ExecuteAsync(filepath, payload, opts)
+	var result *ExecuteResult
+	err := c.withRetry(func() error {
+		handle, err := c.ExecuteAsync(context.Background(), filepath, payload, opts)
+		if err != nil {
+			return err
+		}
+		result, err = handle.Result()
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	return handle.Result()
+	return result, nil
 }
 
-// ExecuteAsync runs an mlld file with a payload and optional state and returns an in-flight handle.
-func (c *Client) ExecuteAsync(filepath string, payload any, opts *ExecuteOptions) (*ExecuteHandle, error) {
+// ExecuteAsync runs an mlld file with a payload and optional state and
+// returns an in-flight handle. ctx bounds the request: canceling it has the
+// same effect as calling handle.Cancel(), so Cancel() isn't the only way to
+// abort.
+func (c *Client) ExecuteAsync(ctx context.Context, filepath string, payload any, opts *ExecuteOptions) (*ExecuteHandle, error) {
 	if opts == nil {
 		opts = &ExecuteOptions{}
 	}
@@ -393,18 +567,19 @@ func (c *Client) ExecuteAsync(filepath string, payload any, opts *ExecuteOptions
 		return nil, err
 	}
 
-	return &ExecuteHandle{
-		request: &requestHandle{
-			client:     c,
-			requestID:  requestID,
-			responseCh: responseCh,
-			timeout:    c.resolveTimeout(opts.Timeout),
-		},
-	}, nil
+	request := newRequestHandle(c, "execute", requestID, responseCh, c.resolveTimeout(opts.Timeout), ctx, opts.EventSink, opts.EventFilter, opts.EventBackpressure, opts.CoalesceInterval, opts.CoalesceMaxDelay)
+
+	return &ExecuteHandle{request: request}, nil
 }
 
 // Analyze performs static analysis on an mlld module without executing it.
+// If a Cache is installed via SetCache, the result is served from cache
+// when filepath's contents match a prior analysis.
 func (c *Client) Analyze(filepath string) (*AnalyzeResult, error) {
+	return c.cachedAnalyze(filepath)
+}
+
+func (c *Client) analyzeUncached(filepath string) (*AnalyzeResult, error) {
 	result, _, err := c.call("analyze", map[string]any{"filepath": filepath}, 0)
 	if err != nil {
 		return nil, err
@@ -432,17 +607,38 @@ func (c *Client) resolveTimeout(timeout time.Duration) time.Duration {
 }
 
 func (c *Client) call(method string, params any, timeout time.Duration) (map[string]any, []StateWrite, error) {
-	requestID, responseCh, err := c.startRequest(method, params)
-	if err != nil {
-		return nil, nil, err
+	c.mu.Lock()
+	hasMiddleware := len(c.middlewares) > 0
+	c.mu.Unlock()
+
+	if !hasMiddleware {
+		requestID, responseCh, err := c.startRequest(method, params)
+		if err != nil {
+			return nil, nil, err
+		}
+		return c.awaitRequest(requestID, responseCh, timeout)
 	}
-	return c.awaitRequest(requestID, responseCh, timeout)
+
+	return c.chainedInvoker(timeout)(context.Background(), method, params)
 }
 
 func (c *Client) startRequest(method string, params any) (uint64, <-chan liveMessage, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	// state:update is exempt from the immediate shuttingDown check: it rides
+	// on a request that's already in-flight, and Shutdown deliberately keeps
+	// the transport open through GraceTimeout so those requests (and the
+	// state updates that steer them) can finish. Only gracePeriodOver, set
+	// once the grace window elapses, cuts state:update off.
+	if method == "state:update" {
+		if c.gracePeriodOver {
+			return 0, nil, errShuttingDown()
+		}
+	} else if c.shuttingDown {
+		return 0, nil, errShuttingDown()
+	}
+
 	if err := c.ensureLiveLocked(); err != nil {
 		return 0, nil, err
 	}
@@ -453,7 +649,7 @@ func (c *Client) startRequest(method string, params any) (uint64, <-chan liveMes
 
 	if err := c.writeRequestLocked(liveRequest{Method: method, ID: requestID, Params: params}); err != nil {
 		delete(c.livePending, requestID)
-		stderr := strings.TrimSpace(c.liveStderr.String())
+		stderr := strings.TrimSpace(transportStderr(c.liveTransport))
 		_ = c.resetLiveLocked()
 		return 0, nil, &Error{
 			Code:    "TRANSPORT_ERROR",
@@ -466,12 +662,26 @@ func (c *Client) startRequest(method string, params any) (uint64, <-chan liveMes
 }
 
 func (c *Client) awaitRequest(requestID uint64, responseCh <-chan liveMessage, timeout time.Duration) (map[string]any, []StateWrite, error) {
+	return c.awaitRequestWithEvents(context.Background(), requestID, responseCh, timeout, nil, nil)
+}
+
+// awaitRequestWithEvents is awaitRequest with an optional onStateWrite
+// callback invoked for every state:write event as it arrives (so watchers
+// registered via ProcessHandle.WatchState see writes in real time rather
+// than only in the aggregated result), an optional eventSink that receives
+// every other event kind as it occurs (state:write events go only to
+// onStateWrite), and a ctx that aborts the request (sending
+// a cancel, same as handle.Cancel()) if it's done before the result arrives.
+func (c *Client) awaitRequestWithEvents(ctx context.Context, requestID uint64, responseCh <-chan liveMessage, timeout time.Duration, onStateWrite func(StateWrite), eventSink func(Event)) (map[string]any, []StateWrite, error) {
 	stateWriteEvents := make([]StateWrite, 0)
 	var timer *time.Timer
 	if timeout > 0 {
 		timer = time.NewTimer(timeout)
 		defer timer.Stop()
 	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
 
 	for {
 		select {
@@ -480,6 +690,11 @@ func (c *Client) awaitRequest(requestID uint64, responseCh <-chan liveMessage, t
 			case liveMessageEvent:
 				if write, ok := parseStateWriteEvent(message.payload); ok {
 					stateWriteEvents = append(stateWriteEvents, write)
+					if onStateWrite != nil {
+						onStateWrite(write)
+					}
+				} else if eventSink != nil {
+					eventSink(eventFromPayload(message.payload))
 				}
 				continue
 			case liveMessageResult:
@@ -488,7 +703,7 @@ func (c *Client) awaitRequest(requestID uint64, responseCh <-chan liveMessage, t
 				}
 				return message.payload, stateWriteEvents, nil
 			case liveMessageClosed:
-				stderr := strings.TrimSpace(c.liveStderr.String())
+				stderr := strings.TrimSpace(transportStderr(c.liveTransport))
 				err := message.err
 				if err == nil {
 					err = io.EOF
@@ -501,6 +716,14 @@ func (c *Client) awaitRequest(requestID uint64, responseCh <-chan liveMessage, t
 			default:
 				continue
 			}
+		case <-ctx.Done():
+			c.sendCancel(requestID)
+			c.removePendingRequest(requestID)
+			return nil, stateWriteEvents, &Error{
+				Code:    "CANCELED",
+				Message: ctx.Err().Error(),
+				Err:     ctx.Err(),
+			}
 		case <-timerChan(timer):
 			c.sendCancel(requestID)
 			c.removePendingRequest(requestID)
@@ -518,6 +741,13 @@ func (c *Client) updateState(requestID uint64, path string, value any, timeout t
 		return &Error{Code: "INVALID_REQUEST", Message: "state update path is required"}
 	}
 
+	c.mu.Lock()
+	injector := c.faultInjector
+	c.mu.Unlock()
+	if injector != nil {
+		injector.OnStateUpdate(requestID, path, value)
+	}
+
 	params := map[string]any{
 		"requestId": requestID,
 		"path":      path,
@@ -575,47 +805,38 @@ func (c *Client) removePendingRequest(requestID uint64) {
 }
 
 func (c *Client) ensureLiveLocked() error {
-	if c.liveCmd != nil && c.liveIn != nil && c.livePending != nil {
+	if c.liveTransport != nil && c.liveIn != nil && c.livePending != nil {
 		return nil
 	}
 
-	args := append([]string{}, c.CommandArgs...)
-	args = append(args, "live", "--stdio")
-
-	cmd := exec.Command(c.Command, args...)
-	if c.WorkingDir != "" {
-		cmd.Dir = c.WorkingDir
-	}
-
-	stdin, err := cmd.StdinPipe()
-	if err != nil {
-		return fmt.Errorf("create live stdin pipe: %w", err)
+	transport := c.Transport
+	if transport == nil {
+		transport = c.defaultStdioTransport()
 	}
 
-	stdout, err := cmd.StdoutPipe()
+	reader, writer, err := transport.Start(context.Background())
 	if err != nil {
-		return fmt.Errorf("create live stdout pipe: %w", err)
-	}
-
-	c.liveStderr.Reset()
-	cmd.Stderr = &c.liveStderr
-
-	if err := cmd.Start(); err != nil {
+		var requestErr *Error
+		if errors.As(err, &requestErr) {
+			return requestErr
+		}
 		return &Error{
 			Code:    "TRANSPORT_ERROR",
-			Message: chooseMessage(strings.TrimSpace(c.liveStderr.String()), err.Error()),
+			Message: chooseMessage(strings.TrimSpace(transportStderr(transport)), err.Error()),
 			Err:     err,
 		}
 	}
 
-	c.liveCmd = cmd
-	c.liveIn = stdin
+	c.liveGen++
+	gen := c.liveGen
+	c.liveTransport = transport
+	c.liveIn = writer
 	c.livePending = make(map[uint64]chan liveMessage)
-	go c.readLoop(stdout)
+	go c.readLoop(reader, gen)
 	return nil
 }
 
-func (c *Client) readLoop(stdout io.Reader) {
+func (c *Client) readLoop(stdout io.Reader, gen uint64) {
 	reader := bufio.NewReader(stdout)
 
 	for {
@@ -624,7 +845,7 @@ func (c *Client) readLoop(stdout io.Reader) {
 		if len(trimmed) > 0 {
 			var payload map[string]any
 			if parseErr := json.Unmarshal(trimmed, &payload); parseErr != nil {
-				c.failAllPending(fmt.Errorf("parse live response: %w", parseErr))
+				c.failAllPending(fmt.Errorf("parse live response: %w", parseErr), gen)
 				return
 			}
 
@@ -643,9 +864,9 @@ func (c *Client) readLoop(stdout io.Reader) {
 
 		if err != nil {
 			if err == io.EOF {
-				c.failAllPending(io.EOF)
+				c.failAllPending(io.EOF, gen)
 			} else {
-				c.failAllPending(err)
+				c.failAllPending(err, gen)
 			}
 			return
 		}
@@ -662,8 +883,13 @@ func (c *Client) dispatchPending(requestID uint64, message liveMessage, complete
 	if ok && complete {
 		delete(c.livePending, requestID)
 	}
+	injector := c.faultInjector
 	c.mu.Unlock()
 
+	if injector != nil {
+		message = injector.AfterReceive(message)
+	}
+
 	if !ok {
 		return
 	}
@@ -674,11 +900,20 @@ func (c *Client) dispatchPending(requestID uint64, message liveMessage, complete
 	}
 }
 
-func (c *Client) failAllPending(err error) {
+// failAllPending fails every pending request on the connection readLoop was
+// reading, unless gen no longer matches c.liveGen: if it doesn't, a newer
+// connection has already replaced this one (see liveGen), and the stale
+// readLoop that just hit EOF/an error has nothing left of its own to clean
+// up.
+func (c *Client) failAllPending(err error, gen uint64) {
 	c.mu.Lock()
+	if gen != c.liveGen {
+		c.mu.Unlock()
+		return
+	}
 	pending := c.livePending
 	c.livePending = nil
-	c.liveCmd = nil
+	c.liveTransport = nil
 	c.liveIn = nil
 	c.mu.Unlock()
 
@@ -695,6 +930,12 @@ func (c *Client) writeRequestLocked(request liveRequest) error {
 		return fmt.Errorf("live transport is not available")
 	}
 
+	if c.faultInjector != nil {
+		if err := c.faultInjector.BeforeSend(request); err != nil {
+			return err
+		}
+	}
+
 	payload, err := json.Marshal(request)
 	if err != nil {
 		return fmt.Errorf("marshal request: %w", err)
@@ -734,16 +975,13 @@ func (c *Client) resetLiveLocked() error {
 		}
 	}
 
-	if c.liveCmd != nil {
-		if c.liveCmd.Process != nil {
-			_ = c.liveCmd.Process.Kill()
-		}
-		if err := c.liveCmd.Wait(); err != nil && firstErr == nil {
+	if c.liveTransport != nil {
+		if err := c.liveTransport.Close(); err != nil && firstErr == nil {
 			firstErr = err
 		}
 	}
 
-	c.liveCmd = nil
+	c.liveTransport = nil
 	c.liveIn = nil
 	return firstErr
 }
@@ -928,6 +1166,10 @@ type Error struct {
 	Code    string
 	Message string
 	Err     error
+
+	// Attempts is the number of attempts made before giving up. It is
+	// only set on Code == "RETRIES_EXHAUSTED".
+	Attempts int
 }
 
 func (e *Error) Error() string {
@@ -0,0 +1,49 @@
+// Package otelmiddleware provides an OpenTelemetry tracing Middleware for
+// mlld.Client, split out of the core mlld package so importing it (and its
+// go.opentelemetry.io dependency tree) is opt-in: Process/Execute-only
+// consumers of mlld never resolve it.
+package otelmiddleware
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mlld-lang/mlld/sdk/go"
+)
+
+// Tracing starts an OpenTelemetry span per live RPC call, with a method
+// attribute, a requestID attribute once the call has assigned one (see
+// mlld.WithRequestID), and a child event per StateWrite.
+func Tracing(tracer trace.Tracer) mlld.Middleware {
+	if tracer == nil {
+		tracer = otel.Tracer("mlld-sdk-go")
+	}
+	return func(next mlld.Invoker) mlld.Invoker {
+		return func(ctx context.Context, method string, params any) (map[string]any, []mlld.StateWrite, error) {
+			ctx, requestID := mlld.WithRequestID(ctx)
+			ctx, span := tracer.Start(ctx, "mlld.live."+method, trace.WithAttributes(
+				attribute.String("mlld.method", method),
+			))
+			defer span.End()
+
+			result, writes, err := next(ctx, method, params)
+			if id, ok := requestID(); ok {
+				span.SetAttributes(attribute.Int64("mlld.request_id", int64(id)))
+			}
+			for _, write := range writes {
+				span.AddEvent("mlld.state_write", trace.WithAttributes(
+					attribute.String("mlld.state_write.path", write.Path),
+				))
+			}
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return result, writes, err
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package mlld
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PoolOptions configures a Pool of live connections.
+type PoolOptions struct {
+	// MinIdle is the number of connections NewPool creates up front.
+	// IdleTimeout and MaxLifetime eviction can close them, and the pool
+	// does not create replacements to get back to MinIdle.
+	MinIdle int
+
+	// MaxActive bounds the total number of live connections the pool will
+	// create. Zero or negative means unbounded.
+	MaxActive int
+
+	// IdleTimeout closes a connection that has sat idle longer than this.
+	// Zero disables idle eviction.
+	IdleTimeout time.Duration
+
+	// MaxLifetime closes a connection once it has been open this long,
+	// regardless of use. Zero disables lifetime eviction.
+	MaxLifetime time.Duration
+
+	// PingInterval is how often idle connections are health-checked with
+	// a lightweight "ping" RPC. IdleTimeout and MaxLifetime eviction run on
+	// the same tick, so zero disables health checks and both evictions.
+	PingInterval time.Duration
+
+	// NewClient builds a fresh *Client for the pool to manage, e.g. one
+	// configured with a particular Command/Transport. If nil, New() is
+	// used for every connection.
+	NewClient func() *Client
+}
+
+// PoolStats mirrors the stats surfaced by connection pools like go-redis's:
+// cumulative counters plus the current set of managed connections.
+type PoolStats struct {
+	Hits       uint64
+	Misses     uint64
+	Timeouts   uint64
+	StaleConns uint64
+	TotalConns int
+	IdleConns  int
+}
+
+type pooledConn struct {
+	client    *Client
+	createdAt time.Time
+	lastUsed  time.Time
+
+	// refs counts concurrent holders of this connection. A saturated pool
+	// hands the same *Client out to more than one caller (see Acquire), so
+	// this must be a count rather than a bool: a bool lets one caller's
+	// Release mark the connection idle while another caller is still
+	// mid-request, letting pingIdleAndEvict close it out from under them.
+	refs int
+}
+
+// Pool manages N live connections, handing out one per in-flight request
+// and health-checking idle connections on PingInterval, mirroring the
+// go-redis connection pool pattern. It also deduplicates concurrent calls
+// to idempotent methods (like Analyze) that share the same parameters via
+// callGroup, a single-flight-style coalescer (see callGroup) kept in this
+// package rather than imported, so depending on Pool doesn't pull in
+// golang.org/x/sync for the one method it needs.
+type Pool struct {
+	opts PoolOptions
+
+	mu    sync.Mutex
+	conns []*pooledConn
+
+	hits, misses, timeouts, staleConns atomic.Uint64
+
+	group callGroup
+
+	closeOnce sync.Once
+	closeCh   chan struct{}
+}
+
+// NewPool creates a Pool and starts its background health-check loop if
+// PingInterval is set.
+func NewPool(opts PoolOptions) *Pool {
+	p := &Pool{opts: opts, closeCh: make(chan struct{})}
+
+	for i := 0; i < opts.MinIdle; i++ {
+		p.conns = append(p.conns, p.newConn())
+	}
+
+	if opts.PingInterval > 0 {
+		go p.healthCheckLoop()
+	}
+
+	return p
+}
+
+func (p *Pool) newConn() *pooledConn {
+	build := p.opts.NewClient
+	if build == nil {
+		build = New
+	}
+	now := time.Now()
+	return &pooledConn{client: build(), createdAt: now, lastUsed: now}
+}
+
+// Acquire hands out an idle connection, creating one if MaxActive allows, or
+// shares the least-recently-used connection otherwise rather than blocking.
+// The caller must call Release when done. A shared connection's ref count
+// (see pooledConn.refs) keeps it from being treated as idle, and evicted,
+// while any holder is still using it.
+func (p *Pool) Acquire() *Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, conn := range p.conns {
+		if conn.refs == 0 {
+			conn.refs = 1
+			conn.lastUsed = time.Now()
+			p.hits.Add(1)
+			return conn.client
+		}
+	}
+
+	if p.opts.MaxActive <= 0 || len(p.conns) < p.opts.MaxActive {
+		conn := p.newConn()
+		conn.refs = 1
+		p.conns = append(p.conns, conn)
+		p.misses.Add(1)
+		return conn.client
+	}
+
+	// Pool is saturated: share the least-recently-used connection rather
+	// than blocking, matching a best-effort pool rather than a strict
+	// semaphore. The client itself multiplexes concurrent requests by
+	// request-id, so sharing it is safe; refs just has to stay accurate so
+	// it isn't evicted while any holder is still using it.
+	oldest := p.conns[0]
+	for _, conn := range p.conns[1:] {
+		if conn.lastUsed.Before(oldest.lastUsed) {
+			oldest = conn
+		}
+	}
+	oldest.refs++
+	oldest.lastUsed = time.Now()
+	p.misses.Add(1)
+	return oldest.client
+}
+
+// Release returns client to the pool for reuse.
+func (p *Pool) Release(client *Client) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, conn := range p.conns {
+		if conn.client == client {
+			if conn.refs > 0 {
+				conn.refs--
+			}
+			conn.lastUsed = time.Now()
+			return
+		}
+	}
+}
+
+// Stats returns a snapshot of the pool's cumulative counters and current
+// connection counts.
+func (p *Pool) Stats() PoolStats {
+	p.mu.Lock()
+	idle := 0
+	for _, conn := range p.conns {
+		if conn.refs == 0 {
+			idle++
+		}
+	}
+	total := len(p.conns)
+	p.mu.Unlock()
+
+	return PoolStats{
+		Hits:       p.hits.Load(),
+		Misses:     p.misses.Load(),
+		Timeouts:   p.timeouts.Load(),
+		StaleConns: p.staleConns.Load(),
+		TotalConns: total,
+		IdleConns:  idle,
+	}
+}
+
+// Analyze runs Client.Analyze on a pooled connection, deduplicating
+// concurrent calls for the same filepath so identical concurrent Analyze
+// calls share a single in-flight request.
+func (p *Pool) Analyze(filepath string) (*AnalyzeResult, error) {
+	key := singleflightKey("analyze", filepath)
+
+	result, err := p.group.Do(key, func() (any, error) {
+		client := p.Acquire()
+		defer p.Release(client)
+		return client.Analyze(filepath)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*AnalyzeResult), nil
+}
+
+// Close stops the health-check loop and closes every pooled connection.
+func (p *Pool) Close() error {
+	var firstErr error
+	p.closeOnce.Do(func() {
+		close(p.closeCh)
+	})
+
+	p.mu.Lock()
+	conns := p.conns
+	p.conns = nil
+	p.mu.Unlock()
+
+	for _, conn := range conns {
+		if err := conn.client.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (p *Pool) healthCheckLoop() {
+	ticker := time.NewTicker(p.opts.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.closeCh:
+			return
+		case <-ticker.C:
+			p.pingIdleAndEvict()
+		}
+	}
+}
+
+func (p *Pool) pingIdleAndEvict() {
+	p.mu.Lock()
+	var idle []*pooledConn
+	var kept []*pooledConn
+	now := time.Now()
+	for _, conn := range p.conns {
+		if conn.refs > 0 {
+			kept = append(kept, conn)
+			continue
+		}
+		if p.opts.MaxLifetime > 0 && now.Sub(conn.createdAt) > p.opts.MaxLifetime {
+			p.staleConns.Add(1)
+			_ = conn.client.Close()
+			continue
+		}
+		if p.opts.IdleTimeout > 0 && now.Sub(conn.lastUsed) > p.opts.IdleTimeout {
+			p.staleConns.Add(1)
+			_ = conn.client.Close()
+			continue
+		}
+		kept = append(kept, conn)
+		idle = append(idle, conn)
+	}
+	p.conns = kept
+	p.mu.Unlock()
+
+	for _, conn := range idle {
+		if _, _, err := conn.client.call("ping", nil, p.opts.PingInterval); err != nil {
+			p.timeouts.Add(1)
+		}
+	}
+}
+
+// singleflightKey builds a stable dedupe key from method and a canonical
+// hash of params so identical concurrent requests collapse to one.
+func singleflightKey(method string, params any) string {
+	serialized, err := json.Marshal(params)
+	if err != nil {
+		serialized = []byte(fmt.Sprintf("%v", params))
+	}
+	sum := sha256.Sum256(serialized)
+	return method + ":" + hex.EncodeToString(sum[:])
+}
+
+// callGroup coalesces concurrent calls that share a key into one in-flight
+// call, the same behavior Pool.Analyze needs from golang.org/x/sync's
+// singleflight.Group, minus the Forgotten/shared-result bookkeeping Pool
+// doesn't use. Kept local so depending on Pool doesn't pull in an extra
+// module for one method.
+type callGroup struct {
+	mu    sync.Mutex
+	calls map[string]*inflightCall
+}
+
+type inflightCall struct {
+	done chan struct{}
+	val  any
+	err  error
+}
+
+// Do runs fn for key, or waits for and returns the result of an identical
+// call already in flight.
+func (g *callGroup) Do(key string, fn func() (any, error)) (any, error) {
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		<-call.done
+		return call.val, call.err
+	}
+
+	call := &inflightCall{done: make(chan struct{})}
+	if g.calls == nil {
+		g.calls = make(map[string]*inflightCall)
+	}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	call.val, call.err = fn()
+	close(call.done)
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+
+	return call.val, call.err
+}
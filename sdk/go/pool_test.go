@@ -0,0 +1,32 @@
+package mlld
+
+import "testing"
+
+// TestPoolAcquireSharedConnectionSurvivesPartialRelease guards against a
+// saturated pool marking a shared connection idle (and eligible for
+// eviction) the moment one of its several concurrent holders releases it
+// while another is still using it.
+func TestPoolAcquireSharedConnectionSurvivesPartialRelease(t *testing.T) {
+	p := NewPool(PoolOptions{MaxActive: 1})
+	defer func() { _ = p.Close() }()
+
+	first := p.Acquire()
+	second := p.Acquire()
+	if first != second {
+		t.Fatalf("expected a saturated pool to share its single connection, got distinct clients")
+	}
+
+	p.Release(first)
+
+	stats := p.Stats()
+	if stats.IdleConns != 0 {
+		t.Fatalf("expected the connection to stay marked in-use while the second holder has it, got %d idle", stats.IdleConns)
+	}
+
+	p.Release(second)
+
+	stats = p.Stats()
+	if stats.IdleConns != 1 {
+		t.Fatalf("expected the connection to go idle once every holder released it, got %d idle", stats.IdleConns)
+	}
+}
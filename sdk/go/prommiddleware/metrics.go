@@ -0,0 +1,83 @@
+// Package prommiddleware provides a Prometheus metrics Middleware for
+// mlld.Client, split out of the core mlld package so importing it (and its
+// github.com/prometheus/client_golang dependency, which has pushed the
+// required Go toolchain version ahead of the core SDK's) is opt-in:
+// Process/Execute-only consumers of mlld never resolve it.
+package prommiddleware
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/mlld-lang/mlld/sdk/go"
+)
+
+// Collector holds the Prometheus metrics Metrics records to: request
+// counts, duration histograms, an in-flight gauge, and per-error-code
+// counters.
+type Collector struct {
+	requests  *prometheus.CounterVec
+	durations *prometheus.HistogramVec
+	inFlight  prometheus.Gauge
+	errors    *prometheus.CounterVec
+}
+
+// NewCollector registers mlld SDK metrics with registerer and returns a
+// Collector ready for Metrics.
+func NewCollector(registerer prometheus.Registerer) *Collector {
+	collector := &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mlld_sdk_requests_total",
+			Help: "Total mlld live RPC calls, by method and outcome.",
+		}, []string{"method", "outcome"}),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "mlld_sdk_request_duration_seconds",
+			Help:    "mlld live RPC call duration in seconds, by method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "mlld_sdk_requests_in_flight",
+			Help: "mlld live RPC calls currently in flight.",
+		}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mlld_sdk_errors_total",
+			Help: "mlld live RPC errors, by error code.",
+		}, []string{"code"}),
+	}
+
+	registerer.MustRegister(collector.requests, collector.durations, collector.inFlight, collector.errors)
+	return collector
+}
+
+// Metrics records Prometheus metrics for every live RPC call using
+// collector.
+func Metrics(collector *Collector) mlld.Middleware {
+	return func(next mlld.Invoker) mlld.Invoker {
+		return func(ctx context.Context, method string, params any) (map[string]any, []mlld.StateWrite, error) {
+			collector.inFlight.Inc()
+			defer collector.inFlight.Dec()
+
+			start := time.Now()
+			result, writes, err := next(ctx, method, params)
+			collector.durations.WithLabelValues(method).Observe(time.Since(start).Seconds())
+
+			if err != nil {
+				outcome := "error"
+				code := "UNKNOWN"
+				var requestErr *mlld.Error
+				if errors.As(err, &requestErr) && requestErr.Code != "" {
+					code = requestErr.Code
+				}
+				collector.requests.WithLabelValues(method, outcome).Inc()
+				collector.errors.WithLabelValues(code).Inc()
+				return result, writes, err
+			}
+
+			collector.requests.WithLabelValues(method, "ok").Inc()
+			return result, writes, nil
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Package rediscache provides a mlld.Cache backed by a shared go-redis
+// client, split out of the core mlld package so importing it (and its
+// github.com/go-redis/redis/v8 dependency) is opt-in: Process/Execute-only
+// consumers of mlld never resolve it.
+package rediscache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/mlld-lang/mlld/sdk/go"
+)
+
+// RedisCache is a mlld.Cache backed by a shared go-redis client, for sharing
+// the Analyze cache across processes.
+type RedisCache struct {
+	client *redis.Client
+	prefix string
+
+	mu    sync.Mutex
+	stats mlld.CacheStats
+}
+
+// New wraps client as a mlld.Cache, namespacing keys under prefix.
+func New(client *redis.Client, prefix string) *RedisCache {
+	return &RedisCache{client: client, prefix: prefix}
+}
+
+func (c *RedisCache) Get(key string) ([]byte, bool) {
+	val, err := c.client.Get(context.Background(), c.prefix+key).Bytes()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		c.stats.Misses++
+		return nil, false
+	}
+	c.stats.Hits++
+	return val, true
+}
+
+func (c *RedisCache) Set(key string, val []byte, ttl time.Duration) {
+	_ = c.client.Set(context.Background(), c.prefix+key, val, ttl).Err()
+}
+
+func (c *RedisCache) Delete(key string) {
+	_ = c.client.Del(context.Background(), c.prefix+key).Err()
+}
+
+// Stats returns a snapshot of the cache's cumulative hit/miss counters.
+// Evictions are managed by Redis itself and aren't tracked client-side.
+func (c *RedisCache) Stats() mlld.CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
@@ -0,0 +1,167 @@
+package mlld
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls how Process/Execute retry on transient transport
+// errors (broken pipe, EOF before response, timeout on write). A zero-value
+// RetryPolicy disables retries.
+type RetryPolicy struct {
+	// InitialDelay is the delay before the first retry.
+	InitialDelay time.Duration
+
+	// Multiplier scales InitialDelay after each attempt. A value <= 1
+	// keeps the delay constant.
+	Multiplier float64
+
+	// MaxAttempts is the total number of attempts, including the first.
+	// Zero or one disables retries.
+	MaxAttempts int
+
+	// Jitter is the maximum fraction (0..1) of the computed delay to add
+	// or subtract at random, to avoid retry storms.
+	Jitter float64
+
+	// Retryable classifies whether err should be retried. If nil, only
+	// broken-pipe/EOF/timeout transport errors are retried.
+	Retryable func(err error) bool
+}
+
+// FaultInjector lets tests simulate dropped frames, delayed responses, or
+// forced subprocess restarts around the live IPC transport. Implementations
+// are invoked synchronously on the request path, so they should return
+// quickly.
+type FaultInjector interface {
+	// BeforeSend is called with the outgoing request before it is written
+	// to the subprocess. Returning a non-nil error aborts the send and is
+	// treated as a transport error (subject to RetryPolicy).
+	BeforeSend(request liveRequest) error
+
+	// AfterReceive is called with each message read from the subprocess
+	// before it is dispatched to its pending request.
+	AfterReceive(message liveMessage) liveMessage
+
+	// OnStateUpdate is called before a state:update request is sent.
+	OnStateUpdate(requestID uint64, path string, value any)
+}
+
+func (c *Client) retryPolicy() RetryPolicy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.retry
+}
+
+// SetRetryPolicy configures automatic retry/backoff for Process and
+// Execute calls.
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.retry = policy
+}
+
+// SetFaultInjector installs a FaultInjector for use in tests that exercise
+// recovery paths without a flaky external process. Pass nil to remove it.
+func (c *Client) SetFaultInjector(injector FaultInjector) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.faultInjector = injector
+}
+
+func isRetryableTransportError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe) {
+		return true
+	}
+	var requestErr *Error
+	if errors.As(err, &requestErr) {
+		return requestErr.Code == "TRANSPORT_ERROR"
+	}
+	return false
+}
+
+func (p RetryPolicy) shouldRetry(err error) bool {
+	if p.MaxAttempts <= 1 {
+		return false
+	}
+	classifier := p.Retryable
+	if classifier == nil {
+		classifier = isRetryableTransportError
+	}
+	return classifier(err)
+}
+
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	base := float64(p.InitialDelay)
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	for i := 1; i < attempt; i++ {
+		base *= multiplier
+	}
+
+	if p.Jitter > 0 {
+		spread := base * p.Jitter
+		base += spread*rand.Float64()*2 - spread
+	}
+	if base < 0 {
+		base = 0
+	}
+	return time.Duration(base)
+}
+
+// withRetry runs op through the configured RetryPolicy, restarting the
+// subprocess and re-establishing framing on a retryable error before
+// replaying op (requests are idempotent by request-id on the CLI side). It
+// surfaces *Error{Code:"RETRIES_EXHAUSTED"} if every attempt fails after at
+// least one retry; an error from an op that was not retried is returned
+// as-is.
+func (c *Client) withRetry(op func() error) error {
+	policy := c.retryPolicy()
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var lastErr error
+	attemptsMade := 0
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		attemptsMade = attempt
+		err := op()
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		if attempt >= maxAttempts || !policy.shouldRetry(err) {
+			break
+		}
+
+		c.mu.Lock()
+		_ = c.resetLiveLocked()
+		c.mu.Unlock()
+
+		if d := policy.delay(attempt); d > 0 {
+			time.Sleep(d)
+		}
+	}
+
+	// Only report RETRIES_EXHAUSTED if a retry actually happened; a single
+	// non-retryable failure on the first attempt should surface as-is so
+	// callers can still branch on its original Code.
+	if attemptsMade > 1 {
+		return &Error{
+			Code:     "RETRIES_EXHAUSTED",
+			Message:  lastErr.Error(),
+			Err:      lastErr,
+			Attempts: attemptsMade,
+		}
+	}
+	return lastErr
+}
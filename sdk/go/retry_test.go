@@ -0,0 +1,192 @@
+package mlld
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithRetryDoesNotWrapNonRetryableFirstAttemptError(t *testing.T) {
+	c := &Client{retry: RetryPolicy{MaxAttempts: 5}}
+
+	calls := 0
+	err := c.withRetry(func() error {
+		calls++
+		return &Error{Code: "RUNTIME_ERROR"}
+	})
+
+	if calls != 1 {
+		t.Fatalf("expected exactly 1 call, got %d", calls)
+	}
+
+	requestErr, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("expected *Error, got %T (%v)", err, err)
+	}
+	if requestErr.Code != "RUNTIME_ERROR" {
+		t.Fatalf("expected original code preserved, got %q", requestErr.Code)
+	}
+	if requestErr.Attempts != 0 {
+		t.Fatalf("expected Attempts unset on a non-retried error, got %d", requestErr.Attempts)
+	}
+}
+
+func TestWithRetryWrapsOnceRetriesActuallyRan(t *testing.T) {
+	c := &Client{retry: RetryPolicy{
+		MaxAttempts: 3,
+		Retryable:   func(err error) bool { return true },
+	}}
+
+	calls := 0
+	err := c.withRetry(func() error {
+		calls++
+		return &Error{Code: "TRANSPORT_ERROR"}
+	})
+
+	if calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls)
+	}
+
+	requestErr, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("expected *Error, got %T (%v)", err, err)
+	}
+	if requestErr.Code != "RETRIES_EXHAUSTED" {
+		t.Fatalf("expected RETRIES_EXHAUSTED, got %q", requestErr.Code)
+	}
+	if requestErr.Attempts != 3 {
+		t.Fatalf("expected Attempts=3, got %d", requestErr.Attempts)
+	}
+}
+
+func TestWithRetrySucceedsAfterTransientFailure(t *testing.T) {
+	c := &Client{retry: RetryPolicy{
+		MaxAttempts: 3,
+		Retryable:   func(err error) bool { return true },
+	}}
+
+	calls := 0
+	err := c.withRetry(func() error {
+		calls++
+		if calls < 2 {
+			return &Error{Code: "TRANSPORT_ERROR"}
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("expected eventual success, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 attempts, got %d", calls)
+	}
+}
+
+// restartableFakeTransport is like fakeTransport but hands out a fresh
+// fakeTransport on every Start call instead of reusing one pair of pipes,
+// so tests can drive a real reconnect through Client.withRetry: fakeTransport
+// itself is single-use and doesn't survive a resetLiveLocked + reconnect.
+// Each Start is announced on startCh so tests can synchronize with it
+// deterministically instead of polling.
+type restartableFakeTransport struct {
+	startCh chan *fakeTransport
+
+	mu      sync.Mutex
+	current *fakeTransport
+}
+
+func newRestartableFakeTransport() *restartableFakeTransport {
+	return &restartableFakeTransport{startCh: make(chan *fakeTransport, 8)}
+}
+
+func (t *restartableFakeTransport) Start(ctx context.Context) (io.Reader, io.WriteCloser, error) {
+	current := newFakeTransport()
+
+	t.mu.Lock()
+	t.current = current
+	t.mu.Unlock()
+
+	t.startCh <- current
+	return current.Start(ctx)
+}
+
+func (t *restartableFakeTransport) Close() error {
+	t.mu.Lock()
+	current := t.current
+	t.mu.Unlock()
+	if current == nil {
+		return nil
+	}
+	return current.Close()
+}
+
+// funcFaultInjector is a FaultInjector with a BeforeSend hook and no-op
+// AfterReceive/OnStateUpdate, for tests that only need to interfere with
+// outgoing requests.
+type funcFaultInjector struct {
+	beforeSend func(liveRequest) error
+}
+
+func (f *funcFaultInjector) BeforeSend(request liveRequest) error {
+	if f.beforeSend != nil {
+		return f.beforeSend(request)
+	}
+	return nil
+}
+
+func (f *funcFaultInjector) AfterReceive(message liveMessage) liveMessage { return message }
+
+func (f *funcFaultInjector) OnStateUpdate(requestID uint64, path string, value any) {}
+
+// TestFaultInjectorDrivesRetryRecovery installs a FaultInjector that drops
+// the first "process" request before it ever reaches the transport, then
+// confirms Client.withRetry recovers by reconnecting and replaying the
+// call, without needing a flaky real subprocess to simulate the drop.
+func TestFaultInjectorDrivesRetryRecovery(t *testing.T) {
+	transport := newRestartableFakeTransport()
+	client := &Client{Transport: transport, Timeout: time.Second}
+	client.SetRetryPolicy(RetryPolicy{MaxAttempts: 2})
+
+	var failedOnce bool
+	var mu sync.Mutex
+	client.SetFaultInjector(&funcFaultInjector{
+		beforeSend: func(request liveRequest) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if request.Method == "process" && !failedOnce {
+				failedOnce = true
+				return errors.New("simulated dropped frame")
+			}
+			return nil
+		},
+	})
+
+	go func() {
+		<-transport.startCh // first Start: BeforeSend fails before any write reaches it
+
+		gen2 := <-transport.startCh // second Start: the replayed request lands here
+		scanner := gen2.requests()
+		for scanner.Scan() {
+			var req struct {
+				ID uint64 `json:"id"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+				continue
+			}
+			gen2.send(`{"result":{"id":` + strconv.FormatUint(req.ID, 10) + `,"output":"hi"}}`)
+		}
+	}()
+
+	output, err := client.Process(`show "hi"`, &ProcessOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if output != "hi" {
+		t.Fatalf("expected output %q, got %q", "hi", output)
+	}
+}
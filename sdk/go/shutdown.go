@@ -0,0 +1,136 @@
+package mlld
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// ShutdownOptions configures graceful shutdown behavior.
+type ShutdownOptions struct {
+	// GraceTimeout bounds how long Shutdown waits for in-flight Process,
+	// Execute, and ProcessAsync handles to complete before sending a soft
+	// cancel to the CLI subprocess. Zero means wait indefinitely.
+	GraceTimeout time.Duration
+
+	// KillTimeout bounds how long Shutdown waits after the soft cancel
+	// before giving up on a clean subprocess exit and killing it. Zero
+	// means wait indefinitely.
+	KillTimeout time.Duration
+}
+
+// NewWithSignals creates a Client that installs a signal handler: on
+// receipt of any of sig, it calls Shutdown with the client's configured
+// grace behavior. If sig is empty it defaults to os.Interrupt.
+func NewWithSignals(sig ...os.Signal) *Client {
+	c := New()
+	if len(sig) == 0 {
+		sig = []os.Signal{os.Interrupt}
+	}
+
+	c.signalCh = make(chan os.Signal, 1)
+	signal.Notify(c.signalCh, sig...)
+
+	go func() {
+		if _, ok := <-c.signalCh; !ok {
+			return
+		}
+		_ = c.Shutdown(context.Background())
+	}()
+
+	return c
+}
+
+// SetShutdownOptions configures the grace and kill timeouts used by
+// Shutdown. It must be called before Shutdown.
+func (c *Client) SetShutdownOptions(opts ShutdownOptions) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.shutdownOpts = opts
+}
+
+// Shutdown stops accepting new Process/Execute/ProcessAsync calls, waits for
+// outstanding handles to complete up to GraceTimeout, sends a soft cancel to
+// the CLI subprocess so `until @state.exit`-style loops can finish, and only
+// then terminates the child. Any Process/Execute/ProcessAsync call made
+// after Shutdown begins returns *Error{Code:"SHUTTING_DOWN"}, and any
+// UpdateState arriving after the grace period returns the same error.
+// Shutdown is idempotent and safe to call more than once.
+func (c *Client) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	if c.shuttingDown {
+		c.mu.Unlock()
+		return nil
+	}
+	c.shuttingDown = true
+	opts := c.shutdownOpts
+	c.mu.Unlock()
+
+	if c.signalCh != nil {
+		signal.Stop(c.signalCh)
+		close(c.signalCh)
+		c.signalCh = nil
+	}
+
+	c.waitForPendingHandles(ctx, opts.GraceTimeout)
+
+	c.mu.Lock()
+	c.gracePeriodOver = true
+	for id := range c.livePending {
+		c.sendCancelLocked(id)
+	}
+	if stdio, ok := c.liveTransport.(*StdioTransport); ok {
+		stdio.KillTimeout = opts.KillTimeout
+	}
+	c.mu.Unlock()
+
+	c.waitForPendingHandles(ctx, opts.KillTimeout)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.resetLiveLocked()
+}
+
+// waitForPendingHandles blocks until no requests are pending, timeout
+// elapses, or ctx is done. A non-positive timeout means wait indefinitely
+// for ctx or for pending requests to drain.
+func (c *Client) waitForPendingHandles(ctx context.Context, timeout time.Duration) {
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		c.mu.Lock()
+		remaining := len(c.livePending)
+		c.mu.Unlock()
+		if remaining == 0 {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Client) sendCancelLocked(requestID uint64) {
+	_ = c.writeRequestLocked(liveRequest{Method: "cancel", ID: requestID})
+}
+
+// errShuttingDown is returned by Process/Execute/ProcessAsync/ExecuteAsync
+// and by UpdateState once Shutdown has begun and its grace period has
+// elapsed.
+func errShuttingDown() error {
+	return &Error{Code: "SHUTTING_DOWN", Message: "client is shutting down"}
+}
@@ -0,0 +1,108 @@
+package mlld
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStartRequestGraceWindow(t *testing.T) {
+	transport := newFakeTransport()
+	c := &Client{
+		liveTransport: transport,
+		liveIn:        transport.clientWriter,
+		livePending:   make(map[uint64]chan liveMessage),
+	}
+
+	// Drain outgoing requests in the background so writeRequestLocked never
+	// blocks on the unbuffered fake pipe.
+	go func() {
+		scanner := transport.requests()
+		for scanner.Scan() {
+		}
+	}()
+
+	c.shuttingDown = true
+
+	if _, _, err := c.startRequest("process", map[string]any{"script": "x"}); err == nil {
+		t.Fatal("expected a new Process call to be rejected as soon as Shutdown begins")
+	}
+
+	if _, _, err := c.startRequest("state:update", map[string]any{"requestId": 1}); err != nil {
+		t.Fatalf("expected state:update to be allowed during the grace period, got %v", err)
+	}
+
+	c.gracePeriodOver = true
+
+	if _, _, err := c.startRequest("state:update", map[string]any{"requestId": 1}); err == nil {
+		t.Fatal("expected state:update to be rejected once the grace period elapses")
+	}
+}
+
+// TestShutdownCancelsPendingAfterGraceAndResetsTransport drives Shutdown
+// end-to-end over fakeTransport: a handle left in flight past GraceTimeout
+// must get a soft cancel, and once it completes, Shutdown must leave the
+// transport reset so a later call is rejected rather than writing to a dead
+// connection.
+func TestShutdownCancelsPendingAfterGraceAndResetsTransport(t *testing.T) {
+	transport := newFakeTransport()
+	client := &Client{Transport: transport, Timeout: time.Second}
+	client.SetShutdownOptions(ShutdownOptions{GraceTimeout: 20 * time.Millisecond, KillTimeout: time.Second})
+
+	var cancelRequestID uint64
+	requestsDone := make(chan struct{})
+	go func() {
+		defer close(requestsDone)
+		scanner := transport.requests()
+
+		scanner.Scan()
+		var processReq struct {
+			Method string `json:"method"`
+			ID     uint64 `json:"id"`
+		}
+		_ = json.Unmarshal(scanner.Bytes(), &processReq)
+
+		// The handle is still in flight, so Shutdown must wait out
+		// GraceTimeout before this line appears: it only arrives once the
+		// grace period elapses and Shutdown sends a soft cancel for every
+		// still-pending request.
+		scanner.Scan()
+		var cancelReq struct {
+			Method string `json:"method"`
+			ID     uint64 `json:"id"`
+		}
+		_ = json.Unmarshal(scanner.Bytes(), &cancelReq)
+		if cancelReq.Method != "cancel" || cancelReq.ID != processReq.ID {
+			t.Errorf("expected a cancel for request %d after the grace period, got %+v", processReq.ID, cancelReq)
+		}
+		atomic.StoreUint64(&cancelRequestID, cancelReq.ID)
+
+		transport.send(`{"result":{"id":` + strconv.FormatUint(processReq.ID, 10) + `,"output":"hi"}}`)
+	}()
+
+	handle, err := client.ProcessAsync(context.Background(), `show "hi"`, &ProcessOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("ProcessAsync failed: %v", err)
+	}
+
+	if err := client.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	select {
+	case <-requestsDone:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Shutdown to send a cancel and drain the pending handle")
+	}
+
+	if got := atomic.LoadUint64(&cancelRequestID); got != handle.RequestID() {
+		t.Fatalf("expected cancel for request %d, got %d", handle.RequestID(), got)
+	}
+
+	if _, err := client.Process(`show "hi"`, nil); err == nil {
+		t.Fatal("expected Process after Shutdown to be rejected rather than writing to the reset transport")
+	}
+}
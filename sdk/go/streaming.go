@@ -0,0 +1,77 @@
+package mlld
+
+// EventBackpressure selects how a handle's Events() channel behaves when
+// the consumer isn't keeping up.
+type EventBackpressure int
+
+const (
+	// EventBackpressureDropOldest discards the oldest buffered event to
+	// make room for the newest one. This is the default: it favors
+	// liveness over completeness for progress/log streams.
+	EventBackpressureDropOldest EventBackpressure = iota
+
+	// EventBackpressureBlock blocks the event dispatch goroutine until the
+	// consumer drains the channel. Use when every event must be observed
+	// and the producer can tolerate being slowed down.
+	EventBackpressureBlock
+)
+
+// Events returns a channel of every event emitted by this in-flight
+// process, filtered by ProcessOptions.EventFilter if set. Events are
+// delivered in order; the channel closes exactly once, after the result is
+// delivered or the transport fails.
+func (h *ProcessHandle) Events() <-chan Event {
+	return h.request.events
+}
+
+// Events returns a channel of every event emitted by this in-flight
+// execute, as above.
+func (h *ExecuteHandle) Events() <-chan Event {
+	return h.request.events
+}
+
+func (h *requestHandle) eventMatchesFilter(event Event) bool {
+	if len(h.eventFilter) == 0 {
+		return true
+	}
+	for _, kind := range h.eventFilter {
+		if kind == event.Kind {
+			return true
+		}
+	}
+	return false
+}
+
+// deliverEvent pushes event onto the handle's Events() channel according to
+// its configured backpressure policy, silently dropping it if the filter
+// excludes its kind.
+func (h *requestHandle) deliverEvent(event Event) {
+	if h.events == nil || !h.eventMatchesFilter(event) {
+		return
+	}
+
+	if h.eventBackpressure == EventBackpressureBlock {
+		h.events <- event
+		return
+	}
+
+	for {
+		select {
+		case h.events <- event:
+			return
+		default:
+			select {
+			case <-h.events:
+			default:
+				return
+			}
+		}
+	}
+}
+
+// closeEvents closes the handle's Events() channel exactly once.
+func (h *requestHandle) closeEvents() {
+	h.eventsClosed.Do(func() {
+		close(h.events)
+	})
+}
@@ -0,0 +1,47 @@
+package mlld
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestEventsDeliversWithoutConcurrentResultCall guards the same event-pump
+// regression as TestWatchStateDeliversWithoutConcurrentResultCall, but for
+// the Events() channel: a caller streaming events shouldn't need to also run
+// Result()/Wait() concurrently to get the pump moving.
+func TestEventsDeliversWithoutConcurrentResultCall(t *testing.T) {
+	transport := newFakeTransport()
+	client := &Client{Transport: transport, Timeout: time.Second}
+
+	go func() {
+		scanner := transport.requests()
+		for scanner.Scan() {
+		}
+	}()
+
+	handle, err := client.ExecuteAsync(context.Background(), "script.mld", nil, &ExecuteOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("ExecuteAsync failed: %v", err)
+	}
+
+	events := handle.Events()
+
+	requestID := strconv.FormatUint(handle.RequestID(), 10)
+	transport.send(`{"event":{"id":` + requestID + `,"type":"show","content":"hello"}}`)
+
+	select {
+	case event := <-events:
+		if event.Kind != EventShow {
+			t.Fatalf("unexpected event kind: %v", event.Kind)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Events() received nothing within 1s with no concurrent Result() call in flight")
+	}
+
+	transport.send(`{"result":{"id":` + requestID + `,"output":"done"}}`)
+	if _, err := handle.Result(); err != nil {
+		t.Fatalf("Result failed: %v", err)
+	}
+}
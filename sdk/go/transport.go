@@ -0,0 +1,250 @@
+package mlld
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// LiveTransport abstracts how the Client reaches the mlld live server.
+// Start establishes the connection and returns a byte stream to read
+// responses from and a writer to send requests on; Close tears the
+// connection down. Client defaults to StdioTransport (today's
+// exec.Command-based subprocess) when Transport is nil.
+type LiveTransport interface {
+	Start(ctx context.Context) (io.Reader, io.WriteCloser, error)
+	Close() error
+}
+
+// ReconnectPolicy controls the exponential backoff network transports use
+// when the initial connection attempt fails. A zero-value ReconnectPolicy
+// disables retrying and fails on the first error.
+//
+// This only backs off the dial inside Start; it does not redial a
+// connection that drops mid-session. A dropped TCP/WebSocket connection
+// surfaces as a TRANSPORT_ERROR the same way a dead stdio subprocess does
+// (see Client.failAllPending), and the next call to ensureLiveLocked
+// reconnects lazily via Start. To retry Process/Execute themselves across
+// that reconnect, use Client.SetRetryPolicy.
+type ReconnectPolicy struct {
+	InitialDelay time.Duration
+	Multiplier   float64
+	MaxAttempts  int
+}
+
+func (p ReconnectPolicy) dial(ctx context.Context, attempt func() (net.Conn, error)) (net.Conn, error) {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	delay := p.InitialDelay
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = 1
+	}
+
+	var lastErr error
+	for i := 0; i < maxAttempts; i++ {
+		conn, err := attempt()
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+
+		if i == maxAttempts-1 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = time.Duration(float64(delay) * multiplier)
+	}
+	return nil, lastErr
+}
+
+// StdioTransport runs the mlld CLI as a child process and speaks the live
+// protocol over its stdin/stdout, exactly as Client did before LiveTransport
+// existed.
+type StdioTransport struct {
+	Command     string
+	CommandArgs []string
+	WorkingDir  string
+
+	// KillTimeout bounds how long Close waits for the child to exit after a
+	// soft SIGTERM before escalating to SIGKILL. Zero uses a 5s default.
+	// Client.Shutdown sets this from ShutdownOptions.KillTimeout.
+	KillTimeout time.Duration
+
+	cmd    *exec.Cmd
+	stderr bytes.Buffer
+}
+
+// Stderr returns the subprocess's captured stderr output, used to enrich
+// error messages when the transport fails.
+func (t *StdioTransport) Stderr() string {
+	return t.stderr.String()
+}
+
+func (t *StdioTransport) Start(ctx context.Context) (io.Reader, io.WriteCloser, error) {
+	args := append([]string{}, t.CommandArgs...)
+	args = append(args, "live", "--stdio")
+
+	cmd := exec.CommandContext(ctx, t.Command, args...)
+	if t.WorkingDir != "" {
+		cmd.Dir = t.WorkingDir
+	}
+	// Run the child in its own process group so Close can signal it and any
+	// descendants it spawns together; otherwise a grandchild that inherits
+	// our stdio pipes can outlive the direct child and keep Wait blocked on
+	// pipe EOF well past both the SIGTERM grace window and the SIGKILL.
+	// See transport_unix.go/transport_windows.go: Windows has no process
+	// group equivalent wired up here, so it falls back to signaling the
+	// direct child only.
+	configureProcessGroup(cmd)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("create live stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("create live stdout pipe: %w", err)
+	}
+
+	t.stderr.Reset()
+	cmd.Stderr = &t.stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, nil, err
+	}
+
+	t.cmd = cmd
+	return stdout, stdin, nil
+}
+
+// Close signals the child to exit gracefully (SIGTERM) and only escalates to
+// an unconditional SIGKILL if it hasn't exited within KillTimeout.
+func (t *StdioTransport) Close() error {
+	cmd := t.cmd
+	t.cmd = nil
+	if cmd == nil || cmd.Process == nil {
+		return nil
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	if err := signalTerm(cmd); err == nil {
+		timeout := t.KillTimeout
+		if timeout <= 0 {
+			timeout = 5 * time.Second
+		}
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(timeout):
+		}
+	}
+
+	_ = signalKill(cmd)
+	return <-done
+}
+
+// TCPTransport speaks the live protocol as newline-delimited JSON-RPC over
+// a plain TCP connection to a shared mlld live daemon, so one daemon can
+// serve multiple Go processes or containers.
+type TCPTransport struct {
+	Addr        string
+	DialTimeout time.Duration
+	Reconnect   ReconnectPolicy
+
+	conn net.Conn
+}
+
+func (t *TCPTransport) Start(ctx context.Context) (io.Reader, io.WriteCloser, error) {
+	conn, err := t.Reconnect.dial(ctx, func() (net.Conn, error) {
+		dialer := net.Dialer{Timeout: t.DialTimeout}
+		return dialer.DialContext(ctx, "tcp", t.Addr)
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("dial live tcp transport: %w", err)
+	}
+	t.conn = conn
+	return conn, conn, nil
+}
+
+func (t *TCPTransport) Close() error {
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn = nil
+	return err
+}
+
+// WebSocketTransport speaks the live protocol as newline-delimited JSON-RPC
+// frames over a WebSocket connection, for daemons reachable only over HTTP
+// (e.g. ws://host/ws/rpc behind a load balancer).
+type WebSocketTransport struct {
+	URL       string
+	Origin    string
+	Reconnect ReconnectPolicy
+
+	ws *websocket.Conn
+}
+
+func (t *WebSocketTransport) Start(ctx context.Context) (io.Reader, io.WriteCloser, error) {
+	origin := t.Origin
+	if origin == "" {
+		origin = "http://localhost"
+	}
+
+	conn, err := t.Reconnect.dial(ctx, func() (net.Conn, error) {
+		return websocket.Dial(t.URL, "", origin)
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("dial live websocket transport: %w", err)
+	}
+
+	ws := conn.(*websocket.Conn)
+	ws.PayloadType = websocket.TextFrame
+	t.ws = ws
+	return ws, ws, nil
+}
+
+func (t *WebSocketTransport) Close() error {
+	if t.ws == nil {
+		return nil
+	}
+	err := t.ws.Close()
+	t.ws = nil
+	return err
+}
+
+// defaultStdioTransport builds the StdioTransport used when Client.Transport
+// is nil, preserving today's exec.Command behavior.
+func (c *Client) defaultStdioTransport() *StdioTransport {
+	return &StdioTransport{
+		Command:     c.Command,
+		CommandArgs: c.CommandArgs,
+		WorkingDir:  c.WorkingDir,
+	}
+}
+
+// transportStderr returns stderr captured by the active transport, if it
+// supports exposing one.
+func transportStderr(t LiveTransport) string {
+	if stdio, ok := t.(*StdioTransport); ok {
+		return stdio.Stderr()
+	}
+	return ""
+}
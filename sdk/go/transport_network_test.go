@@ -0,0 +1,92 @@
+package mlld
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// TestTCPTransportRoundTrip dials a local TCP listener and exchanges one
+// newline-delimited JSON-RPC-style line each way, exercising the reader and
+// writer TCPTransport.Start returns rather than only fakeTransport.
+func TestTCPTransportRoundTrip(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			return
+		}
+		_, _ = conn.Write([]byte("echo:" + line))
+	}()
+
+	transport := &TCPTransport{Addr: listener.Addr().String(), DialTimeout: time.Second}
+	reader, writer, err := transport.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer transport.Close()
+
+	if _, err := writer.Write([]byte("ping\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	line, err := bufio.NewReader(reader).ReadString('\n')
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if line != "echo:ping\n" {
+		t.Fatalf("expected echo:ping, got %q", line)
+	}
+}
+
+// TestWebSocketTransportRoundTrip dials a local WebSocket echo server and
+// exchanges one text frame each way, exercising the reader and writer
+// WebSocketTransport.Start returns rather than only fakeTransport.
+func TestWebSocketTransportRoundTrip(t *testing.T) {
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		ws.PayloadType = websocket.TextFrame
+		line, err := bufio.NewReader(ws).ReadString('\n')
+		if err != nil {
+			return
+		}
+		_, _ = ws.Write([]byte("echo:" + line))
+	}))
+	defer server.Close()
+
+	wsURL := "ws" + server.URL[len("http"):]
+
+	transport := &WebSocketTransport{URL: wsURL}
+	reader, writer, err := transport.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer transport.Close()
+
+	if _, err := writer.Write([]byte("ping\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	line, err := bufio.NewReader(reader).ReadString('\n')
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if line != "echo:ping\n" {
+		t.Fatalf("expected echo:ping, got %q", line)
+	}
+}
@@ -0,0 +1,29 @@
+package mlld
+
+import (
+	"testing"
+	"time"
+)
+
+// TestClientProcessesOverCustomTransport exercises Client.Transport as a
+// pluggable seam: Process should work end-to-end against any LiveTransport,
+// not just the built-in StdioTransport.
+func TestClientProcessesOverCustomTransport(t *testing.T) {
+	transport := newFakeTransport()
+	client := &Client{Transport: transport, Timeout: time.Second}
+
+	go func() {
+		scanner := transport.requests()
+		for scanner.Scan() {
+			transport.send(`{"result":{"id":1,"output":"hello from custom transport"}}`)
+		}
+	}()
+
+	output, err := client.Process("show \"hi\"", &ProcessOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("Process over custom transport failed: %v", err)
+	}
+	if output != "hello from custom transport" {
+		t.Fatalf("unexpected output: %q", output)
+	}
+}
@@ -0,0 +1,31 @@
+package mlld
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStdioTransportCloseSendsSIGTERMBeforeSIGKILL(t *testing.T) {
+	transport := &StdioTransport{
+		Command:     "bash",
+		CommandArgs: []string{"-c", "trap 'exit 0' TERM; sleep 5 & wait"},
+		KillTimeout: 2 * time.Second,
+	}
+
+	if _, _, err := transport.Start(context.Background()); err != nil {
+		t.Fatalf("start transport: %v", err)
+	}
+
+	// Give the trap a moment to install before we signal it.
+	time.Sleep(100 * time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() { done <- transport.Close() }()
+
+	select {
+	case <-done:
+	case <-time.After(1500 * time.Millisecond):
+		t.Fatal("Close did not return promptly after the child honored SIGTERM")
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+
+package mlld
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// configureProcessGroup puts cmd's child in its own process group, so
+// signalTerm/signalKill can reach it and any descendants it spawns
+// together. See the call site in StdioTransport.Start.
+func configureProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// signalTerm sends SIGTERM to the whole process group headed by cmd.
+func signalTerm(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+}
+
+// signalKill sends SIGKILL to the whole process group headed by cmd.
+func signalKill(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
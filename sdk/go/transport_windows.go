@@ -0,0 +1,21 @@
+//go:build windows
+
+package mlld
+
+import "os/exec"
+
+// configureProcessGroup is a no-op on Windows: there is no process-group
+// equivalent wired up here, so signalTerm/signalKill fall back to killing
+// the direct child only, same as before LiveTransport existed.
+func configureProcessGroup(cmd *exec.Cmd) {}
+
+// signalTerm kills the child directly; Windows has no SIGTERM equivalent
+// cheap enough to wire up here.
+func signalTerm(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// signalKill kills the child directly.
+func signalKill(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,167 @@
+package mlld
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ProcessTyped runs script with a typed payload and decodes the result into
+// TResult, round-tripping both through json.Marshal/Unmarshal so callers
+// don't hand-build map[string]any. Required fields on TPayload (tagged
+// `mlld:"required"`) are validated before the RPC is sent, so callers pay
+// for a round trip only on requests that could actually succeed.
+func ProcessTyped[TPayload any, TResult any](c *Client, script string, payload TPayload, opts *ProcessOptions) (TResult, error) {
+	var zero TResult
+
+	if err := validateRequiredFields(payload); err != nil {
+		return zero, err
+	}
+
+	if opts == nil {
+		opts = &ProcessOptions{}
+	}
+	typedOpts := *opts
+	typedOpts.Payload = payload
+
+	output, err := c.Process(script, &typedOpts)
+	if err != nil {
+		return zero, err
+	}
+
+	return decodeTyped[TResult](output)
+}
+
+// ExecuteTyped runs filepath with a typed payload and decodes the execute
+// result's Exports into TResult, as ProcessTyped does for Process.
+func ExecuteTyped[TPayload any, TResult any](c *Client, filepath string, payload TPayload, opts *ExecuteOptions) (TResult, error) {
+	var zero TResult
+
+	if err := validateRequiredFields(payload); err != nil {
+		return zero, err
+	}
+
+	result, err := c.Execute(filepath, payload, opts)
+	if err != nil {
+		return zero, err
+	}
+
+	serialized, err := json.Marshal(result.Exports)
+	if err != nil {
+		return zero, fmt.Errorf("marshal execute exports: %w", err)
+	}
+
+	var typedResult TResult
+	if err := json.Unmarshal(serialized, &typedResult); err != nil {
+		return zero, fmt.Errorf("decode typed execute result: %w", err)
+	}
+	return typedResult, nil
+}
+
+// decodeTyped unmarshals raw into TResult, falling back to treating raw as
+// a plain string if TResult is string-shaped and raw isn't valid JSON.
+func decodeTyped[TResult any](raw string) (TResult, error) {
+	var result TResult
+	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+		if asString, ok := any(&result).(*string); ok {
+			*asString = raw
+			return result, nil
+		}
+		return result, fmt.Errorf("decode typed process result: %w", err)
+	}
+	return result, nil
+}
+
+// validateRequiredFields reflects over payload's exported struct fields and
+// returns an *Error{Code:"INVALID_REQUEST"} if any field tagged
+// `mlld:"required"` is at its zero value, avoiding a round trip for
+// obvious client errors.
+func validateRequiredFields(payload any) error {
+	value := reflect.ValueOf(payload)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var missing []string
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("mlld")
+		if !hasTagOption(tag, "required") {
+			continue
+		}
+		if value.Field(i).IsZero() {
+			missing = append(missing, jsonFieldName(field))
+		}
+	}
+
+	if len(missing) > 0 {
+		return &Error{
+			Code:    "INVALID_REQUEST",
+			Message: fmt.Sprintf("missing required field(s): %s", strings.Join(missing, ", ")),
+		}
+	}
+	return nil
+}
+
+func hasTagOption(tag, option string) bool {
+	for _, part := range strings.Split(tag, ",") {
+		if part == option {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	jsonTag := field.Tag.Get("json")
+	name := strings.Split(jsonTag, ",")[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// StateStore is a typed wrapper around ProcessHandle/ExecuteHandle
+// UpdateState, letting callers write against a schema instead of
+// hand-building map[string]any paths.
+type StateStore[T any] struct {
+	update func(path string, value any) error
+}
+
+// NewStateStore wraps an UpdateState-capable handle (ProcessHandle or
+// ExecuteHandle) with a typed StateStore.
+func NewStateStore[T any](update func(path string, value any) error) *StateStore[T] {
+	return &StateStore[T]{update: update}
+}
+
+// NewProcessStateStore returns a StateStore[T] backed by h.UpdateState. Go
+// doesn't allow generic methods, so this free function stands in for a
+// StateStore method on ProcessHandle.
+func NewProcessStateStore[T any](h *ProcessHandle) *StateStore[T] {
+	return NewStateStore[T](h.UpdateState)
+}
+
+// NewExecuteStateStore returns a StateStore[T] backed by h.UpdateState, as
+// NewProcessStateStore does for ExecuteHandle.
+func NewExecuteStateStore[T any](h *ExecuteHandle) *StateStore[T] {
+	return NewStateStore[T](h.UpdateState)
+}
+
+// Set writes value at path against the store's schema T. The caller is
+// responsible for path referring to a field that exists on T; Set does not
+// itself validate the path, since state:// paths can address nested or
+// dynamic keys that don't always map 1:1 onto Go struct fields.
+func (s *StateStore[T]) Set(path string, value any) error {
+	return s.update(path, value)
+}
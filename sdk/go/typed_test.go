@@ -0,0 +1,68 @@
+package mlld
+
+import "testing"
+
+type typedPayload struct {
+	Name string `json:"name" mlld:"required"`
+	Age  int    `json:"age"`
+}
+
+func TestValidateRequiredFieldsReportsMissing(t *testing.T) {
+	err := validateRequiredFields(typedPayload{Age: 5})
+	if err == nil {
+		t.Fatal("expected an error for a missing required field")
+	}
+	requestErr, ok := err.(*Error)
+	if !ok || requestErr.Code != "INVALID_REQUEST" {
+		t.Fatalf("expected *Error{Code:INVALID_REQUEST}, got %#v", err)
+	}
+}
+
+func TestValidateRequiredFieldsPassesWhenSet(t *testing.T) {
+	if err := validateRequiredFields(typedPayload{Name: "ok"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestValidateRequiredFieldsIgnoresNonStruct(t *testing.T) {
+	if err := validateRequiredFields("just a string"); err != nil {
+		t.Fatalf("expected non-struct payloads to skip validation, got %v", err)
+	}
+}
+
+func TestDecodeTypedFallsBackToStringOnInvalidJSON(t *testing.T) {
+	result, err := decodeTyped[string]("not json")
+	if err != nil {
+		t.Fatalf("expected string fallback, got error: %v", err)
+	}
+	if result != "not json" {
+		t.Fatalf("unexpected result: %q", result)
+	}
+}
+
+func TestDecodeTypedDecodesStruct(t *testing.T) {
+	result, err := decodeTyped[typedPayload](`{"name":"ok","age":5}`)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if result.Name != "ok" || result.Age != 5 {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+}
+
+func TestStateStoreSetDelegatesToUpdate(t *testing.T) {
+	var gotPath string
+	var gotValue any
+	store := NewStateStore[typedPayload](func(path string, value any) error {
+		gotPath = path
+		gotValue = value
+		return nil
+	})
+
+	if err := store.Set("name", "new-name"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if gotPath != "name" || gotValue != "new-name" {
+		t.Fatalf("unexpected delegate call: path=%q value=%v", gotPath, gotValue)
+	}
+}
@@ -0,0 +1,167 @@
+package mlld
+
+import (
+	"strings"
+	"sync"
+)
+
+// watchSubscription delivers StateWrites for a single path (or prefix, if
+// path ends in ".*") to a bounded, drop-oldest channel.
+type watchSubscription struct {
+	path   string
+	prefix bool
+	ch     chan StateWrite
+	mu     sync.Mutex
+	closed bool
+}
+
+func newWatchSubscription(path string, buffer int) *watchSubscription {
+	prefix := strings.HasSuffix(path, ".*")
+	if prefix {
+		path = strings.TrimSuffix(path, ".*")
+	}
+	if buffer <= 0 {
+		buffer = 64
+	}
+	return &watchSubscription{
+		path:   path,
+		prefix: prefix,
+		ch:     make(chan StateWrite, buffer),
+	}
+}
+
+func (s *watchSubscription) matches(write StateWrite) bool {
+	if s.prefix {
+		return write.Path == s.path || strings.HasPrefix(write.Path, s.path+".")
+	}
+	return write.Path == s.path
+}
+
+// deliver pushes write onto the subscription's channel, dropping the oldest
+// buffered write if the channel is full.
+func (s *watchSubscription) deliver(write StateWrite) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	for {
+		select {
+		case s.ch <- write:
+			return
+		default:
+			select {
+			case <-s.ch:
+			default:
+				return
+			}
+		}
+	}
+}
+
+func (s *watchSubscription) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.ch)
+}
+
+// WatchState subscribes to every StateWrite the running script emits for
+// path (or for any path under path, if path ends in ".*"), delivered over a
+// bounded ring buffer with a drop-oldest policy. The returned cancel
+// function tears down the subscription without affecting the in-flight
+// process. WatchState lets callers drive `loop(...) until @state.exit`
+// patterns reactively instead of polling: watch until a predicate holds,
+// then call UpdateState.
+func (h *ProcessHandle) WatchState(path string) (<-chan StateWrite, func() error, error) {
+	return h.request.watchState(path)
+}
+
+// WatchState subscribes to StateWrites emitted by this execute, as above.
+func (h *ExecuteHandle) WatchState(path string) (<-chan StateWrite, func() error, error) {
+	return h.request.watchState(path)
+}
+
+// Snapshot returns the most recently observed state for this in-flight
+// process, derived from the StateWrites seen so far.
+func (h *ProcessHandle) Snapshot() (map[string]any, error) {
+	return h.request.snapshot()
+}
+
+// Snapshot returns the most recently observed state for this in-flight
+// execute, derived from the StateWrites seen so far.
+func (h *ExecuteHandle) Snapshot() (map[string]any, error) {
+	return h.request.snapshot()
+}
+
+func (h *requestHandle) watchState(path string) (<-chan StateWrite, func() error, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, nil, &Error{Code: "INVALID_REQUEST", Message: "watch path is required"}
+	}
+
+	sub := newWatchSubscription(path, 64)
+
+	h.watchMu.Lock()
+	h.watches = append(h.watches, sub)
+	h.watchMu.Unlock()
+
+	cancel := func() error {
+		h.watchMu.Lock()
+		for i, existing := range h.watches {
+			if existing == sub {
+				h.watches = append(h.watches[:i], h.watches[i+1:]...)
+				break
+			}
+		}
+		h.watchMu.Unlock()
+		sub.close()
+		return nil
+	}
+
+	return sub.ch, cancel, nil
+}
+
+func (h *requestHandle) snapshot() (map[string]any, error) {
+	h.watchMu.Lock()
+	defer h.watchMu.Unlock()
+
+	snapshot := make(map[string]any, len(h.stateByPath))
+	for path, value := range h.stateByPath {
+		snapshot[path] = value
+	}
+	return snapshot, nil
+}
+
+// fanOutStateWrite records write in the handle's latest-state snapshot and
+// delivers it to every matching watch subscription.
+func (h *requestHandle) fanOutStateWrite(write StateWrite) {
+	h.watchMu.Lock()
+	if h.stateByPath == nil {
+		h.stateByPath = make(map[string]any)
+	}
+	h.stateByPath[write.Path] = write.Value
+	watches := append([]*watchSubscription(nil), h.watches...)
+	h.watchMu.Unlock()
+
+	for _, sub := range watches {
+		if sub.matches(write) {
+			sub.deliver(write)
+		}
+	}
+}
+
+// closeWatches tears down every subscription still registered on h, called
+// once the request completes.
+func (h *requestHandle) closeWatches() {
+	h.watchMu.Lock()
+	watches := h.watches
+	h.watches = nil
+	h.watchMu.Unlock()
+
+	for _, sub := range watches {
+		sub.close()
+	}
+}
@@ -0,0 +1,51 @@
+package mlld
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestWatchStateDeliversWithoutConcurrentResultCall guards against the event
+// pump only running inside Result()/Wait(): a caller following the
+// documented WatchState pattern should see writes as they arrive even if it
+// never calls Result() concurrently.
+func TestWatchStateDeliversWithoutConcurrentResultCall(t *testing.T) {
+	transport := newFakeTransport()
+	client := &Client{Transport: transport, Timeout: time.Second}
+
+	go func() {
+		scanner := transport.requests()
+		for scanner.Scan() {
+		}
+	}()
+
+	handle, err := client.ProcessAsync(context.Background(), "loop", &ProcessOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("ProcessAsync failed: %v", err)
+	}
+
+	ch, cancel, err := handle.WatchState("progress")
+	if err != nil {
+		t.Fatalf("WatchState failed: %v", err)
+	}
+	defer cancel()
+
+	requestID := strconv.FormatUint(handle.RequestID(), 10)
+	transport.send(`{"event":{"id":` + requestID + `,"type":"state:write","write":{"path":"progress","value":1}}}`)
+
+	select {
+	case write := <-ch:
+		if write.Path != "progress" {
+			t.Fatalf("unexpected write path: %q", write.Path)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("WatchState received nothing within 1s with no concurrent Result() call in flight")
+	}
+
+	transport.send(`{"result":{"id":` + requestID + `,"output":"done"}}`)
+	if _, err := handle.Result(); err != nil {
+		t.Fatalf("Result failed: %v", err)
+	}
+}